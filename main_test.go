@@ -0,0 +1,39 @@
+package main
+
+import (
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestWriteVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		info *debug.BuildInfo
+		want string
+	}{
+		{"unknown", nil, "sample-go-ebiten (unknown version)\n"},
+		{
+			"vcs",
+			&debug.BuildInfo{
+				GoVersion: "go1.26.0",
+				Main:      debug.Module{Path: "github.com/otakakot/sample-go-ebiten", Version: "v1.2.3"},
+				Settings: []debug.BuildSetting{
+					{Key: "-compiler", Value: "gc"},
+					{Key: "vcs.revision", Value: "abc123"},
+					{Key: "vcs.modified", Value: "false"},
+				},
+			},
+			"github.com/otakakot/sample-go-ebiten v1.2.3 (go1.26.0)\nvcs.revision=abc123\nvcs.modified=false\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			writeVersion(&b, tt.info)
+			if got := b.String(); got != tt.want {
+				t.Errorf("writeVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
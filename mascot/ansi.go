@@ -0,0 +1,119 @@
+package mascot
+
+import (
+	"image/color"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ansiEscape は端末向けの ANSI エスケープシーケンスに一致する。
+// CSI（色を変える SGR やカーソル移動など）、OSC（ウィンドウタイトルやリンクなど）と、文字集合の切り替えなどの ESC に数文字続くシーケンスを対象にする。
+var ansiEscape = regexp.MustCompile(`\x1b(?:\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(?:\x07|\x1b\\)|[ -/]*[0-~])`)
+
+// stripANSI は s から ANSI エスケープシーケンスを取り除く。
+func stripANSI(s string) string {
+	return ansiEscape.ReplaceAllString(s, "")
+}
+
+// ansiPalette は SGR の 8/16 色の文字色（30〜37、90〜97）に対応する色。xterm の既定の配色に合わせる。
+var ansiPalette = [16]color.RGBA{
+	{0x00, 0x00, 0x00, 0xff}, {0xcd, 0x00, 0x00, 0xff}, {0x00, 0xcd, 0x00, 0xff}, {0xcd, 0xcd, 0x00, 0xff},
+	{0x00, 0x00, 0xee, 0xff}, {0xcd, 0x00, 0xcd, 0xff}, {0x00, 0xcd, 0xcd, 0xff}, {0xe5, 0xe5, 0xe5, 0xff},
+	{0x7f, 0x7f, 0x7f, 0xff}, {0xff, 0x00, 0x00, 0xff}, {0x00, 0xff, 0x00, 0xff}, {0xff, 0xff, 0x00, 0xff},
+	{0x5c, 0x5c, 0xff, 0xff}, {0xff, 0x00, 0xff, 0xff}, {0x00, 0xff, 0xff, 0xff}, {0xff, 0xff, 0xff, 0xff},
+}
+
+// textStyle のうち、ANSI の文字色を保持するビット。ansiPalette の番号に1を足した値を置き、0 は色の指定なしを表す。
+const (
+	ansiColorShift           = 8
+	ansiColorMask  textStyle = 0x1f << ansiColorShift
+)
+
+// withANSIColor は st の文字色を ansiPalette の idx 番の色に置き換えたスタイルを返す。idx が負なら色の指定を外す。
+func (st textStyle) withANSIColor(idx int) textStyle {
+	st &^= ansiColorMask
+	if idx >= 0 {
+		st |= textStyle(idx+1) << ansiColorShift
+	}
+	return st
+}
+
+// ansiColor は st に ANSI の文字色があればその色を返す。
+func (st textStyle) ansiColor() (color.Color, bool) {
+	idx := int(st&ansiColorMask>>ansiColorShift) - 1
+	if idx < 0 || idx >= len(ansiPalette) {
+		return nil, false
+	}
+	return ansiPalette[idx], true
+}
+
+// parseANSIColors はメッセージから ANSI エスケープシーケンスを取り除き、
+// SGR で指定された 8/16 色の文字色を各文字のスタイルにして返す。色の指定は改行をまたいで続く。
+// 文字色以外の SGR（背景色・256色など）と、SGR 以外のシーケンスは取り除くだけにする。
+// 記法はシーケンスを取り除いた後のテキストで parseMarkup が解釈するため、色で囲んだ *強調* も効く。
+// シーケンスがなければ styles は nil を返す。
+func parseANSIColors(s string) (string, []textStyle) {
+	matches := ansiEscape.FindAllStringIndex(s, -1)
+	if len(matches) == 0 {
+		return s, nil
+	}
+	var b strings.Builder
+	var out []textStyle
+	cur := -1 // 現在の文字色の ansiPalette の番号。-1 は指定なし
+	prev := 0
+	emit := func(s string) {
+		for _, r := range s {
+			b.WriteRune(r)
+			out = append(out, textStyle(0).withANSIColor(cur))
+		}
+	}
+	for _, m := range matches {
+		emit(s[prev:m[0]])
+		cur = applySGR(s[m[0]:m[1]], cur)
+		prev = m[1]
+	}
+	emit(s[prev:])
+	return b.String(), out
+}
+
+// applySGR はエスケープシーケンス seq が SGR（ESC [ ... m）であれば、文字色 cur に適用した結果を返す。
+// 0（または省略）と 39 で色の指定を外し、30〜37 と 90〜97 で色を変える。
+// 38・48 の 256色・RGB の指定は、続く引数ごと読み飛ばす。
+func applySGR(seq string, cur int) int {
+	params, ok := strings.CutPrefix(seq, "\x1b[")
+	if !ok {
+		return cur
+	}
+	params, ok = strings.CutSuffix(params, "m")
+	if !ok {
+		return cur
+	}
+	codes := strings.Split(params, ";")
+	for i := 0; i < len(codes); i++ {
+		code := 0 // 省略は 0 とみなす
+		if codes[i] != "" {
+			n, err := strconv.Atoi(codes[i])
+			if err != nil {
+				continue
+			}
+			code = n
+		}
+		switch {
+		case code == 0 || code == 39:
+			cur = -1
+		case code >= 30 && code <= 37:
+			cur = code - 30
+		case code >= 90 && code <= 97:
+			cur = code - 90 + 8
+		case code == 38 || code == 48:
+			// 38;5;n と 38;2;r;g;b の引数を読み飛ばす
+			if i+1 < len(codes) && codes[i+1] == "5" {
+				i += 2
+			} else if i+1 < len(codes) && codes[i+1] == "2" {
+				i += 4
+			}
+		}
+	}
+	return cur
+}
@@ -0,0 +1,55 @@
+package mascot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseANSIColorsRuns(t *testing.T) {
+	green := textStyle(0).withANSIColor(2)
+	tests := []struct {
+		name string
+		src  string
+		want []styledRun
+	}{
+		{
+			name: "colored word",
+			src:  "\x1b[32mOK\x1b[0m done",
+			want: []styledRun{{"OK", green}, {" done", 0}},
+		},
+		{
+			name: "color continues across a line break",
+			src:  "\x1b[32mone\ntwo\x1b[0m",
+			want: []styledRun{{"one", green}, {"\n", 0}, {"two", green}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plain, styles := parseMarkup(parseANSIColors(tt.src))
+			got := styledRuns([]rune(plain), styles)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("runs of %q = %v, want %v", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripANSI(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"color", "\x1b[31merror\x1b[0m: failed", "error: failed"},
+		{"cursor movement", "\x1b[2K\x1b[1Gdone", "done"},
+		{"window title", "\x1b]0;title\x07hello", "hello"},
+		{"plain text", "hello", "hello"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripANSI(tt.src); got != tt.want {
+				t.Errorf("stripANSI(%q) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
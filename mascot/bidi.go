@@ -0,0 +1,152 @@
+package mascot
+
+import (
+	"slices"
+	"unicode"
+)
+
+// 右から左に書く文字（ヘブライ文字・アラビア文字など）を含む行は、描画前に見た目の順序に並べ替える。
+// Unicode の双方向アルゴリズムを簡略化したもので、行の最初の強い方向を持つ文字で行の向きを決め、
+// 同じ向きの文字の並びごとに順序を入れ替える。アラビア文字の字形の変化（シェーピング）には対応しない。
+
+// textDir は文字の書字方向。
+type textDir int
+
+const (
+	dirNeutral textDir = iota // 空白や記号など、前後の文字に従う
+	dirLTR
+	dirRTL
+)
+
+// rtlScripts は右から左に書く文字体系。
+var rtlScripts = []*unicode.RangeTable{
+	unicode.Hebrew,
+	unicode.Arabic,
+	unicode.Syriac,
+	unicode.Thaana,
+	unicode.Nko,
+}
+
+// mirroredRunes は右から左の並びで左右を反転して描画する括弧。
+var mirroredRunes = map[rune]rune{
+	'(': ')', ')': '(',
+	'[': ']', ']': '[',
+	'{': '}', '}': '{',
+	'<': '>', '>': '<',
+}
+
+// runeDir は文字の書字方向を返す。
+func runeDir(r rune) textDir {
+	switch {
+	case unicode.In(r, rtlScripts...):
+		return dirRTL
+	case unicode.IsLetter(r) || unicode.IsDigit(r):
+		return dirLTR
+	}
+	return dirNeutral
+}
+
+// isRTLLine は行の最初の強い方向を持つ文字が右から左に書く文字かどうかを返す。
+func isRTLLine(runes []rune) bool {
+	for _, r := range runes {
+		if d := runeDir(r); d != dirNeutral {
+			return d == dirRTL
+		}
+	}
+	return false
+}
+
+// visualOrder は論理順の文字とスタイルを、左から描画する見た目の順序に並べ替える。
+// rtl は行の向き。右から左に書く文字を含まない行はそのまま返す。
+func visualOrder(runes []rune, styles []textStyle, rtl bool) ([]rune, []textStyle) {
+	dirs := make([]textDir, len(runes))
+	hasRTL := false
+	for i, r := range runes {
+		dirs[i] = runeDir(r)
+		// 結合文字は基底の文字と同じ向きにする
+		if i > 0 && unicode.Is(unicode.M, r) {
+			dirs[i] = dirs[i-1]
+		}
+		hasRTL = hasRTL || dirs[i] == dirRTL
+	}
+	if !hasRTL {
+		return runes, styles
+	}
+
+	// 空白や記号は、前後の文字の向きが同じならその向きに、異なれば行の向きに従う
+	base := dirLTR
+	if rtl {
+		base = dirRTL
+	}
+	for i := 0; i < len(dirs); {
+		if dirs[i] != dirNeutral {
+			i++
+			continue
+		}
+		j := i
+		for j < len(dirs) && dirs[j] == dirNeutral {
+			j++
+		}
+		d := base
+		if i > 0 && j < len(dirs) && dirs[i-1] == dirs[j] {
+			d = dirs[i-1]
+		}
+		for k := i; k < j; k++ {
+			dirs[k] = d
+		}
+		i = j
+	}
+
+	// 同じ向きの並びごとに分け、右から左の並びは書記素クラスタの順序を反転する
+	type span struct {
+		runes  []rune
+		styles []textStyle
+	}
+	var spans []span
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && dirs[j] == dirs[i] {
+			j++
+		}
+		sp := span{runes: slices.Clone(runes[i:j]), styles: make([]textStyle, j-i)}
+		for k := i; k < j; k++ {
+			sp.styles[k-i] = styleAt(styles, k)
+		}
+		if dirs[i] == dirRTL {
+			sp.runes, sp.styles = reverseClusters(sp.runes, sp.styles)
+			for k, r := range sp.runes {
+				if m, ok := mirroredRunes[r]; ok {
+					sp.runes[k] = m
+				}
+			}
+		}
+		spans = append(spans, sp)
+		i = j
+	}
+	// 右から左の行では並びの順序も反転する
+	if rtl {
+		slices.Reverse(spans)
+	}
+
+	outRunes := make([]rune, 0, len(runes))
+	outStyles := make([]textStyle, 0, len(runes))
+	for _, sp := range spans {
+		outRunes = append(outRunes, sp.runes...)
+		outStyles = append(outStyles, sp.styles...)
+	}
+	return outRunes, outStyles
+}
+
+// reverseClusters は文字とスタイルを書記素クラスタの単位で逆順に並べる。結合文字は基底の文字の後ろに残す。
+func reverseClusters(runes []rune, styles []textStyle) ([]rune, []textStyle) {
+	outRunes := make([]rune, 0, len(runes))
+	outStyles := make([]textStyle, 0, len(styles))
+	end := len(runes)
+	for _, c := range slices.Backward(splitClusters(runes)) {
+		start := end - len(c)
+		outRunes = append(outRunes, runes[start:end]...)
+		outStyles = append(outStyles, styles[start:end]...)
+		end = start
+	}
+	return outRunes, outStyles
+}
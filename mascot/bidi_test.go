@@ -0,0 +1,26 @@
+package mascot
+
+import "testing"
+
+func TestVisualOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		rtl  bool
+		want string
+	}{
+		{"hebrew", "שלום", true, "םולש"},
+		{"hebrew with ltr word", "שלום world", true, "world םולש"},
+		// 結合文字は反転後も基底の文字の後ろに付ける
+		{"hebrew with a point", "שָלום", true, "םולשָ"},
+		{"arabic with a haraka", "مَرحبا", true, "ابحرمَ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := visualOrder([]rune(tt.line), nil, tt.rtl)
+			if string(got) != tt.want {
+				t.Errorf("visualOrder(%q, %v) = %q, want %q", tt.line, tt.rtl, string(got), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package mascot
+
+import (
+	"image/color"
+	"math/rand/v2"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// まばたきのパラメータ
+const (
+	blinkFrames      = 6   // 目を閉じているフレーム数
+	blinkIntervalMin = 3.0 // まばたきの最短間隔（秒）
+	blinkIntervalMax = 6.0 // まばたきの最長間隔（秒）
+)
+
+// gopherEye は画像内の目の位置。値は画像サイズに対する比率。
+type gopherEye struct {
+	x, y float64 // 中心
+	r    float64 // 半径（白目の外周）
+}
+
+// defaultGopherEyes は埋め込みGopher画像の目の位置。
+var defaultGopherEyes = []gopherEye{
+	{x: 0.312, y: 0.206, r: 0.082},
+	{x: 0.503, y: 0.107, r: 0.084},
+}
+
+// gopherSkinColor は埋め込みGopher画像の体の色。まぶたの塗りに使う。
+var gopherSkinColor = color.RGBA{R: 208, G: 182, B: 152, A: 255}
+
+// nextBlinkFrames は次のまばたきまでのフレーム数を返す。一定間隔にならないよう揺らぎを持たせる。
+func nextBlinkFrames() int {
+	sec := blinkIntervalMin + rand.Float64()*(blinkIntervalMax-blinkIntervalMin)
+	return int(sec * float64(ebiten.TPS()))
+}
+
+// updateBlink はまばたきのタイマーを進める。
+func (gm *Game) updateBlink() {
+	if gm.eyes == nil && !gm.hasBlinkExpression() {
+		return
+	}
+	if gm.blinkFrame > 0 {
+		gm.blinkFrame--
+		return
+	}
+	gm.blinkTimer--
+	if gm.blinkTimer <= 0 {
+		gm.blinkFrame = blinkFrames
+		gm.blinkTimer = nextBlinkFrames()
+	}
+}
+
+// hasBlinkExpression はスプライトシートに目を閉じたフレームがあるかを返す。
+func (gm *Game) hasBlinkExpression() bool {
+	if gm.sprites == nil {
+		return false
+	}
+	_, ok := gm.sprites.expressions[expressionBlink]
+	return ok
+}
+
+// drawEyelids は目の上に体の色のまぶたを重ね、閉じた目の線を描く。
+func (gm *Game) drawEyelids(screen *ebiten.Image, ly layout) {
+	w := float64(gm.gopherImage.Bounds().Dx()) * ly.gopherScale
+	h := float64(gm.gopherImage.Bounds().Dy()) * ly.gopherScale
+
+	for _, eye := range gm.eyes {
+		cx := float32(ly.gopherX + eye.x*w)
+		cy := float32(ly.gopherY + eye.y*h)
+		r := float32(eye.r * w)
+
+		// 白目の輪郭線まで覆うよう少し大きめに塗る
+		s := float32(gm.deviceScale)
+		vector.FillCircle(screen, cx*s, cy*s, r*1.1*s, gopherSkinColor, gm.antiAlias)
+
+		var p vector.Path
+		p.MoveTo(cx-r, cy)
+		p.QuadTo(cx, cy+r*0.6, cx+r, cy)
+		vector.StrokePath(screen, scalePath(&p, s), &vector.StrokeOptions{
+			Width: strokeWidth * s, LineCap: vector.LineCapRound,
+		}, &vector.DrawPathOptions{
+			AntiAlias: gm.antiAlias, ColorScale: colorScale(color.Black),
+		})
+	}
+}
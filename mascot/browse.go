@@ -0,0 +1,98 @@
+package mascot
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// recentSize はホイールで遡れる、最近表示したメッセージの件数。
+const recentSize = 20
+
+// recordRecent は表示したメッセージを最近のメッセージの先頭に加える。件数を超えた古いものは取り除く。
+func (gm *Game) recordRecent(msg message) {
+	gm.recent = append([]message{msg}, gm.recent...)
+	if len(gm.recent) > recentSize {
+		gm.recent = gm.recent[:recentSize]
+	}
+}
+
+// browseStep は最近のメッセージを step 件遡った（負なら新しい方へ戻った）後の位置を返す。
+// index は recent の位置で、-1 は最新の表示（ライブ）を表す。n は recent の件数、
+// liveShown はライブでメッセージ（recent[0]）を表示中かどうか。表示中なら recent[0] は飛ばして遡る。
+// 遡れる範囲を超えた分は端で止め、新しい方へ戻りきったらライブに戻る。
+func browseStep(index, step, n int, liveShown bool) int {
+	first := 0
+	if liveShown {
+		first = 1
+	}
+	if first >= n {
+		return -1
+	}
+	if index < 0 {
+		if step <= 0 {
+			return -1
+		}
+		index = first - 1
+	}
+	index += step
+	if index < first {
+		return -1
+	}
+	return min(index, n-1)
+}
+
+// updateBrowse はGopherの上でホイールを回したら最近のメッセージを遡り、吹き出しに表示し直す。
+// 上に回すと古いメッセージへ、下に回すと新しいメッセージへ移り、最新まで戻るとライブの表示に戻る。
+// 遡っている間は表示時間を止め、届いたメッセージは戻るまで待たせる。
+func (gm *Game) updateBrowse(cx, cy int) {
+	if gm.browseIndex >= 0 && !gm.hasMessage {
+		// 遡って表示したメッセージをクリックなどで消したらライブに戻る
+		gm.stopBrowsing()
+		return
+	}
+	_, dy := ebiten.Wheel()
+	if dy == 0 || gm.noticing || !gm.hitGopher(cx, cy) {
+		gm.wheelAcc = 0
+		return
+	}
+	// トラックパッドの細かい回転は1件分たまるまで待つ
+	gm.wheelAcc += dy
+	step := int(gm.wheelAcc)
+	if step == 0 {
+		return
+	}
+	gm.wheelAcc -= float64(step)
+
+	if gm.browseIndex < 0 {
+		gm.liveShown = gm.hasMessage
+		gm.liveTimer = gm.msgTimer
+	}
+	index := browseStep(gm.browseIndex, step, len(gm.recent), gm.liveShown)
+	if index == gm.browseIndex {
+		return
+	}
+	if index < 0 {
+		gm.stopBrowsing()
+		return
+	}
+	gm.browseIndex = index
+	gm.showRecent(gm.recent[index])
+}
+
+// showRecent は遡ったメッセージを履歴や最近のメッセージに記録せずに表示する。読み上げは行わない。
+func (gm *Game) showRecent(msg message) {
+	sp := gm.speaker
+	gm.speaker = nil
+	gm.showMessage(msg)
+	gm.speaker = sp
+	gm.revealedChars = gm.layout.charCount()
+}
+
+// stopBrowsing はライブの表示に戻る。遡り始めたときに表示していたメッセージがあれば、残りの表示時間ごと元に戻す。
+func (gm *Game) stopBrowsing() {
+	if gm.liveShown && len(gm.recent) > 0 {
+		gm.showRecent(gm.recent[0])
+		gm.msgTimer = gm.liveTimer
+	} else {
+		gm.clearMessage()
+	}
+	gm.browseIndex = -1
+	gm.wheelAcc = 0
+}
@@ -0,0 +1,33 @@
+package mascot
+
+import "testing"
+
+func TestBrowseStep(t *testing.T) {
+	tests := []struct {
+		name      string
+		index     int
+		step      int
+		n         int
+		liveShown bool
+		want      int
+	}{
+		{"start from live", -1, 1, 5, false, 0},
+		{"start skips the live message", -1, 1, 5, true, 1},
+		{"start with several steps", -1, 3, 5, true, 3},
+		{"forward from live stays live", -1, -1, 5, false, -1},
+		{"back one", 1, 1, 5, false, 2},
+		{"stops at the oldest", 3, 5, 5, false, 4},
+		{"stays at the oldest", 4, 1, 5, true, 4},
+		{"forward one", 3, -1, 5, true, 2},
+		{"newest returns to live", 0, -1, 5, false, -1},
+		{"newest skipping the live message returns to live", 1, -1, 5, true, -1},
+		{"far forward returns to live", 4, -10, 5, false, -1},
+		{"no messages", -1, 1, 0, false, -1},
+		{"only the live message", -1, 1, 1, true, -1},
+	}
+	for _, tt := range tests {
+		if got := browseStep(tt.index, tt.step, tt.n, tt.liveShown); got != tt.want {
+			t.Errorf("%s: browseStep(%d, %d, %d, %v) = %d, want %d", tt.name, tt.index, tt.step, tt.n, tt.liveShown, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package mascot
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 吹き出しのドラッグのパラメータ
+const (
+	bubbleDragThreshold = 4  // 吹き出しのクリックをドラッグとみなす移動量(px)
+	aimedTailLength     = 25 // Gopherに向け直したしっぽの長さ(px)
+)
+
+// setBubbleOffset は吹き出しを既定の位置から (dx, dy) ずらし、しっぽをGopherの頭へ向け直す。
+// 吹き出しはウィンドウ (sw, sh) の内側に収める。
+func (ly *layout) setBubbleOffset(dx, dy float32, sw, sh int) {
+	bx := ly.bubbleX - ly.bubbleOffX + dx
+	by := ly.bubbleY - ly.bubbleOffY + dy
+	bx = max(min(bx, float32(sw)-ly.bubbleW), 0)
+	by = max(min(by, float32(sh)-ly.bubbleH), 0)
+	ly.bubbleOffX += bx - ly.bubbleX
+	ly.bubbleOffY += by - ly.bubbleY
+	ly.bubbleX, ly.bubbleY = bx, by
+
+	ly.tailX, ly.tailDir = calcTail(ly.headX, ly.bubbleX, ly.bubbleW, ly.bubbleRadius)
+	ly.tailAimed = ly.bubbleOffX != 0 || ly.bubbleOffY != 0
+	if !ly.tailAimed {
+		return
+	}
+	// しっぽの基部からGopherの頭に向かう方向に先端を置く
+	baseX, baseY := ly.tailBase()
+	vx, vy := ly.headX-baseX, ly.headY-baseY
+	d := float32(math.Hypot(float64(vx), float64(vy)))
+	if d == 0 {
+		ly.tailTipX, ly.tailTipY = baseX, baseY
+		return
+	}
+	l := min(d, aimedTailLength)
+	ly.tailTipX, ly.tailTipY = baseX+vx/d*l, baseY+vy/d*l
+}
+
+// tailBase はしっぽの基部の中心を返す。吹き出しの下辺、吹き出しがGopherの下にある場合は上辺にある。
+func (ly layout) tailBase() (float32, float32) {
+	if ly.bubbleBelow {
+		return ly.tailX, ly.bubbleY + 1
+	}
+	return ly.tailX, ly.bubbleY + ly.bubbleH - 1
+}
+
+// updateBubbleDrag は吹き出しを押している間の操作を処理する。
+// 動かさずに離した場合はクリックとして、リンクの上ならブラウザで開き、それ以外ならメッセージを消す。
+// 動かした場合は吹き出しの位置を変えて保存する。
+func (gm *Game) updateBubbleDrag(cx, cy int) {
+	dx, dy := cx-gm.dragStartX, cy-gm.dragStartY
+	if !gm.bubbleDragging && max(dx, -dx)+max(dy, -dy) >= bubbleDragThreshold {
+		gm.bubbleDragging = true
+	}
+
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		if gm.bubbleDragging {
+			gm.layout.setBubbleOffset(gm.bubbleStartX+float32(dx), gm.bubbleStartY+float32(dy), gm.screenWidth, gm.screenHeight)
+			gm.bubbleOffX, gm.bubbleOffY = gm.layout.bubbleOffX, gm.layout.bubbleOffY
+		}
+		return
+	}
+
+	if gm.bubbleDragging {
+		gm.saveState()
+	} else if url := gm.linkAt(cx, cy); url != "" {
+		if err := openURL(url); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	} else {
+		gm.clearMessage()
+	}
+	gm.bubblePressed = false
+	gm.bubbleDragging = false
+}
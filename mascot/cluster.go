@@ -0,0 +1,57 @@
+package mascot
+
+import "unicode"
+
+// 絵文字やアクセント付きの文字は、複数のコードポイントを組み合わせて1文字（書記素クラスタ）として表示されることがある。
+// 折り返しで途中が分かれないよう、次のコードポイントは直前の文字とひとまとまりに扱う。
+//   - 結合文字（e + ◌́ = é など）
+//   - ゼロ幅接合子(ZWJ)と、その直後の文字（👨‍👩‍👧 など）
+//   - 異体字セレクタ（❤️ など）と肌の色の修飾子（👋🏽 など）
+//   - キーキャップ（1️⃣ など）とタグ文字（地域の旗）
+//   - 2つ1組の地域指示記号（🇯🇵 などの国旗）
+const (
+	zeroWidthJoiner = '\u200d'
+	combiningKeycap = '\u20e3'
+)
+
+// joinsCluster は r が直前の文字 prev と合わせて1つの文字として表示されるかどうかを返す。
+func joinsCluster(prev []rune, r rune) bool {
+	if len(prev) == 0 {
+		return false
+	}
+	switch {
+	case unicode.Is(unicode.M, r): // 結合文字
+		return true
+	case r == zeroWidthJoiner, r == combiningKeycap:
+		return true
+	case r >= '\ufe00' && r <= '\ufe0f': // 異体字セレクタ
+		return true
+	case r >= 0x1f3fb && r <= 0x1f3ff: // 肌の色の修飾子
+		return true
+	case r >= 0xe0020 && r <= 0xe007f: // タグ文字
+		return true
+	case prev[len(prev)-1] == zeroWidthJoiner:
+		return true
+	case isRegionalIndicator(r):
+		return len(prev) == 1 && isRegionalIndicator(prev[0])
+	}
+	return false
+}
+
+// isRegionalIndicator は国旗を構成する地域指示記号かどうかを返す。
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// splitClusters は文字の並びを、1つの文字として表示されるまとまりごとに分ける。
+func splitClusters(runes []rune) [][]rune {
+	var clusters [][]rune
+	for _, r := range runes {
+		if n := len(clusters); n > 0 && joinsCluster(clusters[n-1], r) {
+			clusters[n-1] = append(clusters[n-1], r)
+			continue
+		}
+		clusters = append(clusters, []rune{r})
+	}
+	return clusters
+}
@@ -0,0 +1,31 @@
+package mascot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrapTextKeepsClusters(t *testing.T) {
+	face := testFace(t)
+	// 空白を含まないため、折り返した行をつなげると元の文字列に戻る
+	msg := strings.Repeat("e\u0301🇯🇵👨‍👩‍👧1️⃣👋🏽", 4)
+	boundaries := map[int]bool{}
+	n := 0
+	for _, c := range splitClusters([]rune(msg)) {
+		n += len(c)
+		boundaries[n] = true
+	}
+	for maxWidth := 10.0; maxWidth <= measureText(face, msg); maxWidth += 7 {
+		wrapped := wrapText(msg, face, maxWidth, false)
+		if joined := strings.ReplaceAll(wrapped, "\n", ""); joined != msg {
+			t.Fatalf("width %v: wrapText(%q) = %q, lost characters", maxWidth, msg, wrapped)
+		}
+		end := 0
+		for _, line := range strings.Split(wrapped, "\n") {
+			end += len([]rune(line))
+			if !boundaries[end] {
+				t.Errorf("width %v: line %q ends inside a cluster", maxWidth, line)
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package mascot
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/opentype"
+)
+
+// codeFence はコードブロックの開始・終了を表す行の先頭。
+const codeFence = "```"
+
+// codeBackground はコードブロックの背景色。
+var codeBackground = color.NRGBA{R: 0xee, G: 0xee, B: 0xee, A: 0xff}
+
+// parseMarkup はメッセージの記法を解釈し、表示するテキストと各文字のスタイルを返す。
+// base は s の各文字にあらかじめ付いているスタイル（ANSI の文字色）で、記法による強調と合わせて返す。nil でもよい。
+// ``` だけの行で囲まれた行はコードブロックとして textCode を付け、強調の記法は解釈しない。
+// 閉じる ``` がない場合はメッセージの最後までをコードブロックとする。
+func parseMarkup(s string, base []textStyle) (string, []textStyle) {
+	var lines []string
+	var styles []textStyle
+	inCode := false
+	offset := 0 // line の先頭の、s での文字の位置
+	for _, line := range strings.Split(s, "\n") {
+		lineBase := base[min(offset, len(base)):]
+		offset += utf8.RuneCountInString(line) + 1
+		if strings.HasPrefix(strings.TrimSpace(line), codeFence) {
+			inCode = !inCode
+			continue
+		}
+		if len(lines) > 0 {
+			styles = append(styles, 0) // 改行
+		}
+		if inCode {
+			for i := range []rune(line) {
+				styles = append(styles, styleAt(lineBase, i)|textCode)
+			}
+			lines = append(lines, line)
+			continue
+		}
+		plain, st := parseEmphasis(line, lineBase)
+		styles = append(styles, st...)
+		lines = append(lines, plain)
+	}
+	return strings.Join(lines, "\n"), styles
+}
+
+// wrapMessage は parseMarkup で解釈したテキストを折り返す。
+// コードブロックの行は等幅フォントで計測し、単語ではなく文字単位で折り返す。
+// それ以外の行は文字体系 sc の規則で折り返し、CJK のメッセージには CJK 用のフォントがあればそれで計測する。
+func (gm *Game) wrapMessage(plain string, styles []textStyle, sc script, maxWidth float64) string {
+	paras := strings.Split(plain, "\n")
+	offset := 0
+	for i, para := range paras {
+		n := len([]rune(para))
+		if styleAt(styles, offset)&textCode != 0 {
+			paras[i] = wrapChars(para, gm.codeFace, maxWidth)
+		} else {
+			paras[i] = wrapText(para, gm.runFace(styleAt(styles, offset)), maxWidth, sc == scriptCJK)
+		}
+		offset += n + 1
+	}
+	return strings.Join(paras, "\n")
+}
+
+// wrapChars は1行の文字列を指定のピクセル幅で文字単位に折り返す。空白も取り除かない。
+// 結合文字や絵文字の途中では折り返さない。
+func wrapChars(para string, face text.Face, maxWidth float64) string {
+	var result []string
+	var line []rune
+	var lineW float64
+	for _, c := range splitClusters([]rune(para)) {
+		cw := measureText(face, string(c))
+		w := appendedWidth(face, line, lineW, c, cw)
+		if len(line) > 0 && w > maxWidth {
+			result = append(result, string(line))
+			line, w = nil, cw
+		}
+		line = append(line, c...)
+		lineW = w
+	}
+	return strings.Join(append(result, string(line)), "\n")
+}
+
+// loadCodeFace はコードブロック用の等幅フォントを size の大きさで読み込む。
+// 等幅フォントにない文字（日本語など）は fallback で描画する。scale は newFontFace と同じく描画先のデバイススケール。
+func loadCodeFace(size int, scale float64, fallback text.Face) (text.Face, error) {
+	tt, err := opentype.Parse(gomono.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("parse mono font: %w", err)
+	}
+	mono, err := newFontFace(tt, size, scale)
+	if err != nil {
+		return nil, err
+	}
+	mf, err := text.NewMultiFace(mono, fallback)
+	if err != nil {
+		return nil, fmt.Errorf("new multi face: %w", err)
+	}
+	return mf, nil
+}
+
+// drawCodeBackground はコードブロックの行の背景を描画する。y は行の上端で、座標は論理座標をデバイススケール s 倍して描く。
+// 続くコードブロックの行の背景はつながって1つの箱になる。
+func drawCodeBackground(dst *ebiten.Image, x, y, w, h, s float64) {
+	const pad = 4
+	vector.FillRect(dst, float32((x-pad)*s), float32(y*s), float32((w+pad*2)*s), float32(h*s), codeBackground, false)
+}
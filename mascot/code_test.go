@@ -0,0 +1,25 @@
+package mascot
+
+import "testing"
+
+func TestCodeFaceMonospace(t *testing.T) {
+	gm := newTestGame(t, DefaultOptions())
+	want := measureText(gm.codeFace, "i")
+	for _, s := range []string{"l", "m", "W", "0", " ", "_"} {
+		if got := measureText(gm.codeFace, s); got != want {
+			t.Errorf("advance of %q = %v, want %v like %q", s, got, want, "i")
+		}
+	}
+
+	// コードブロックの行は文字数が同じなら同じ幅になる
+	gm.showMessage(parseMessage("```\\niiii\\nWWWW\\n```"))
+	lines := gm.layout.lines
+	if len(lines) != 2 || styleAt(gm.textStyles[0], 0)&textCode == 0 || styleAt(gm.textStyles[1], 0)&textCode == 0 {
+		t.Fatalf("lines %q with styles %v, want two code lines", lines, gm.textStyles)
+	}
+	w0 := gm.measureRuns(styledRuns([]rune(lines[0]), gm.textStyles[0]))
+	w1 := gm.measureRuns(styledRuns([]rune(lines[1]), gm.textStyles[1]))
+	if w0 != w1 || w0 != 4*want {
+		t.Errorf("code line widths = %v and %v, want both %v", w0, w1, 4*want)
+	}
+}
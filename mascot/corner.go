@@ -0,0 +1,136 @@
+package mascot
+
+import "image"
+
+// corner はウィンドウを配置する画面の角。
+// メッセージに応じてウィンドウがリサイズされても、この角の位置は変わらない。
+type corner int
+
+const (
+	cornerBottomRight corner = iota
+	cornerBottomLeft
+	cornerTopRight
+	cornerTopLeft
+)
+
+// parseCorner は角の名前（GOPHER_CORNER の値）を corner に変換する。未知の値は右下として扱う。
+func parseCorner(s string) corner {
+	switch s {
+	case "bottom-left":
+		return cornerBottomLeft
+	case "top-right":
+		return cornerTopRight
+	case "top-left":
+		return cornerTopLeft
+	default:
+		return cornerBottomRight
+	}
+}
+
+// placement は吹き出しをGopherの上下どちらに置くか。
+type placement int
+
+const (
+	placementAuto  placement = iota // 上に置き、ウィンドウが画面の上にはみ出す場合は下に置く
+	placementAbove                  // 常に上に置く
+	placementBelow                  // 常に下に置き、しっぽを上向きにする
+)
+
+// parsePlacement は配置の名前（GOPHER_PLACEMENT の値）を placement に変換する。未知の値は自動として扱う。
+func parsePlacement(s string) placement {
+	switch s {
+	case "above":
+		return placementAbove
+	case "below":
+		return placementBelow
+	default:
+		return placementAuto
+	}
+}
+
+// left は左側の角かどうかを返す。
+func (c corner) left() bool {
+	return c == cornerBottomLeft || c == cornerTopLeft
+}
+
+// top は上側の角かどうかを返す。
+func (c corner) top() bool {
+	return c == cornerTopRight || c == cornerTopLeft
+}
+
+// initialWindowPosition はモニター上のウィンドウの初期位置を返す。monitor はウィンドウ位置の座標でのモニターの範囲。
+// 保存された状態 st があればその位置を復元し、なければ指定の角に配置する。
+// いずれの場合もウィンドウがモニターからはみ出さないよう収める。
+func initialWindowPosition(c corner, monitor image.Rectangle, sw, sh int, st *windowState) (int, int) {
+	x, y := monitor.Max.X-sw, monitor.Max.Y-sh
+	if c.left() {
+		x = monitor.Min.X
+	}
+	if c.top() {
+		y = monitor.Min.Y
+	}
+
+	if st != nil {
+		x, y = st.Right-sw, st.Bottom-sh
+		if c.left() {
+			x = st.Left
+		}
+		if c.top() {
+			y = st.Top
+		}
+	}
+
+	x = min(max(x, monitor.Min.X), monitor.Max.X-sw)
+	y = min(max(y, monitor.Min.Y), monitor.Max.Y-sh)
+	return x, y
+}
+
+// resizedWindowPosition はウィンドウを (oldW, oldH) から (newW, newH) にリサイズする際に、
+// 角の位置を保つための新しいウィンドウ位置を返す。
+func (c corner) resizedWindowPosition(wx, wy, oldW, oldH, newW, newH int) (int, int) {
+	if !c.left() {
+		wx += oldW - newW
+	}
+	if !c.top() {
+		wy += oldH - newH
+	}
+	return wx, wy
+}
+
+// clampWindowPosition はウィンドウ全体がモニターの範囲 monitor 内に収まるよう位置を補正する。
+// ウィンドウがモニターより大きい場合は左上を優先して合わせる。
+func clampWindowPosition(wx, wy, sw, sh int, monitor image.Rectangle) (int, int) {
+	wx = max(min(wx, monitor.Max.X-sw), monitor.Min.X)
+	wy = max(min(wy, monitor.Max.Y-sh), monitor.Min.Y)
+	return wx, wy
+}
+
+// snapWindowPosition はウィンドウの端がモニターの範囲 monitor の端から threshold 以内にあれば、その端に合わせた位置を返す。
+func snapWindowPosition(wx, wy, sw, sh int, monitor image.Rectangle, threshold int) (int, int) {
+	switch {
+	case abs(wx-monitor.Min.X) <= threshold:
+		wx = monitor.Min.X
+	case abs(monitor.Max.X-(wx+sw)) <= threshold:
+		wx = monitor.Max.X - sw
+	}
+	switch {
+	case abs(wy-monitor.Min.Y) <= threshold:
+		wy = monitor.Min.Y
+	case abs(monitor.Max.Y-(wy+sh)) <= threshold:
+		wy = monitor.Max.Y - sh
+	}
+	return wx, wy
+}
+
+// abs は整数の絶対値を返す。
+func abs(n int) int {
+	return max(n, -n)
+}
+
+// clampDragPosition はドラッグ中のウィンドウ位置を、Gopherの矩形 g（ウィンドウ内の座標）が
+// モニターからはみ出さない範囲に補正する。ウィンドウの透明な余白ははみ出してもよい。
+func clampDragPosition(wx, wy int, g, monitor image.Rectangle) (int, int) {
+	wx = max(min(wx, monitor.Max.X-g.Max.X), monitor.Min.X-g.Min.X)
+	wy = max(min(wy, monitor.Max.Y-g.Max.Y), monitor.Min.Y-g.Min.Y)
+	return wx, wy
+}
@@ -0,0 +1,166 @@
+package mascot
+
+import (
+	"image"
+	"testing"
+)
+
+func TestSnapWindowPosition(t *testing.T) {
+	monitor := image.Rect(0, 0, 1920, 1080)
+	const sw, sh = 300, 400
+	tests := []struct {
+		name         string
+		wx, wy       int
+		threshold    int
+		wantX, wantY int
+	}{
+		{"far from the edges", 500, 500, 20, 500, 500},
+		{"near the left edge", 10, 500, 20, 0, 500},
+		{"past the left edge", -15, 500, 20, 0, 500},
+		{"near the right edge", 1605, 500, 20, 1620, 500},
+		{"near the top edge", 500, 20, 20, 500, 0},
+		{"near the bottom edge", 500, 670, 20, 500, 680},
+		{"near a corner", 5, 675, 20, 0, 680},
+		{"just outside the threshold", 21, 500, 20, 21, 500},
+		{"snapping disabled", 10, 10, 0, 10, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := snapWindowPosition(tt.wx, tt.wy, sw, sh, monitor, tt.threshold)
+			if x != tt.wantX || y != tt.wantY {
+				t.Errorf("snapWindowPosition(%d, %d) = (%d, %d), want (%d, %d)", tt.wx, tt.wy, x, y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
+
+func TestClampDragPosition(t *testing.T) {
+	monitor := image.Rect(0, 0, 1920, 1080)
+	// ウィンドウ内のGopherの矩形。周りの透明な余白はモニターからはみ出してもよい
+	g := image.Rect(200, 300, 500, 600)
+	tests := []struct {
+		name         string
+		wx, wy       int
+		wantX, wantY int
+	}{
+		{"inside", 100, 100, 100, 100},
+		{"margin past the left edge", -150, 100, -150, 100},
+		{"off the left edge", -1000, 100, -200, 100},
+		{"off the right edge", 3000, 100, 1420, 100},
+		{"off the top edge", 100, -1000, 100, -300},
+		{"off the bottom edge", 100, 5000, 100, 480},
+		{"off a corner", -1000, 5000, -200, 480},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := clampDragPosition(tt.wx, tt.wy, g, monitor)
+			if x != tt.wantX || y != tt.wantY {
+				t.Errorf("clampDragPosition(%d, %d) = (%d, %d), want (%d, %d)", tt.wx, tt.wy, x, y, tt.wantX, tt.wantY)
+			}
+			if r := g.Add(image.Pt(x, y)); !r.In(monitor) {
+				t.Errorf("gopher at %v is not on the monitor", r)
+			}
+		})
+	}
+}
+
+func TestWindowPositionMonitorOrigin(t *testing.T) {
+	const sw, sh = 300, 400
+	// 主モニターの左に置いた、原点が負のモニターと、右下にずれたモニター
+	monitors := []image.Rectangle{
+		image.Rect(-1280, -200, 0, 824),
+		image.Rect(1920, 300, 3840, 1380),
+	}
+	for _, m := range monitors {
+		for _, c := range []corner{cornerBottomRight, cornerBottomLeft, cornerTopRight, cornerTopLeft} {
+			x, y := initialWindowPosition(c, m, sw, sh, nil)
+			want := image.Pt(m.Max.X-sw, m.Max.Y-sh)
+			if c.left() {
+				want.X = m.Min.X
+			}
+			if c.top() {
+				want.Y = m.Min.Y
+			}
+			if got := image.Pt(x, y); got != want {
+				t.Errorf("monitor %v, corner %d: initialWindowPosition = %v, want %v", m, c, got, want)
+			}
+		}
+
+		// 原点の外側に出た位置はモニターの端に戻す
+		x, y := clampWindowPosition(m.Min.X-50, m.Max.Y, sw, sh, m)
+		if want := image.Pt(m.Min.X, m.Max.Y-sh); image.Pt(x, y) != want {
+			t.Errorf("monitor %v: clampWindowPosition = (%d, %d), want %v", m, x, y, want)
+		}
+
+		// 端への吸着とドラッグの制限も、モニターの原点を基準にする
+		x, y = snapWindowPosition(m.Min.X+10, m.Max.Y-sh-10, sw, sh, m, 20)
+		if want := image.Pt(m.Min.X, m.Max.Y-sh); image.Pt(x, y) != want {
+			t.Errorf("monitor %v: snapWindowPosition = (%d, %d), want %v", m, x, y, want)
+		}
+		g := image.Rect(50, 60, 250, 360)
+		x, y = clampDragPosition(m.Min.X-1000, m.Min.Y-1000, g, m)
+		if want := image.Pt(m.Min.X-g.Min.X, m.Min.Y-g.Min.Y); image.Pt(x, y) != want {
+			t.Errorf("monitor %v: clampDragPosition = (%d, %d), want %v", m, x, y, want)
+		}
+	}
+}
+
+func TestClampWindowPosition(t *testing.T) {
+	const sw, sh = 300, 400
+	primary := image.Rect(0, 0, 1920, 1080)
+	tests := []struct {
+		name         string
+		monitor      image.Rectangle
+		wx, wy       int
+		wantX, wantY int
+	}{
+		{"inside", primary, 100, 100, 100, 100},
+		{"off the right and bottom", primary, 1800, 1000, 1620, 680},
+		{"off the left and top", primary, -50, -50, 0, 0},
+		// ウィンドウがモニターより大きい場合は左上を合わせる
+		{"window larger than the monitor", image.Rect(0, 0, 200, 300), 50, 50, 0, 0},
+		{"window larger than an offset monitor", image.Rect(1920, 100, 2120, 400), 3000, 900, 1920, 100},
+		{"negative origin", image.Rect(-1280, -200, 0, 824), 100, -500, -300, -200},
+		{"offset origin", image.Rect(1920, 300, 3840, 1380), 100, 100, 1920, 300},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := clampWindowPosition(tt.wx, tt.wy, sw, sh, tt.monitor)
+			if x != tt.wantX || y != tt.wantY {
+				t.Errorf("clampWindowPosition(%d, %d) = (%d, %d), want (%d, %d)", tt.wx, tt.wy, x, y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
+
+func TestInitialWindowPosition(t *testing.T) {
+	const sw, sh = 300, 400
+	monitor := image.Rect(0, 0, 1920, 1080)
+	// モニターが小さくなるなどして、保存した位置がはみ出している状態
+	offScreen := &windowState{Left: -500, Top: -500, Right: 3000, Bottom: 2000}
+	tests := []struct {
+		c            corner
+		st           *windowState
+		wantX, wantY int
+	}{
+		{cornerBottomRight, nil, 1620, 680},
+		{cornerBottomLeft, nil, 0, 680},
+		{cornerTopRight, nil, 1620, 0},
+		{cornerTopLeft, nil, 0, 0},
+		{cornerBottomRight, offScreen, 1620, 680},
+		{cornerBottomLeft, offScreen, 0, 680},
+		{cornerTopRight, offScreen, 1620, 0},
+		{cornerTopLeft, offScreen, 0, 0},
+		{cornerBottomRight, &windowState{Right: 1000, Bottom: 900}, 700, 500},
+		{cornerTopLeft, &windowState{Left: 100, Top: 50}, 100, 50},
+	}
+	for _, tt := range tests {
+		x, y := initialWindowPosition(tt.c, monitor, sw, sh, tt.st)
+		if x != tt.wantX || y != tt.wantY {
+			t.Errorf("corner %d, state %+v: initialWindowPosition = (%d, %d), want (%d, %d)", tt.c, tt.st, x, y, tt.wantX, tt.wantY)
+		}
+		if r := image.Rect(x, y, x+sw, y+sh); !r.In(monitor) {
+			t.Errorf("corner %d, state %+v: window %v is not on the monitor", tt.c, tt.st, r)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package mascot
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// HiDPI のモニターでは、論理ピクセルの大きさの画面に描画すると拡大表示されて文字がぼやける。
+// レイアウトとウィンドウサイズは論理ピクセルのまま計算し、描画先の画面だけをデバイススケール倍の
+// 解像度にする。描画では論理座標をデバイススケール倍し、文字はデバイススケール倍の DPI のフェイスで描く。
+
+// currentDeviceScale は表示中のモニターのデバイススケールを返す。
+func currentDeviceScale() float64 {
+	s := ebiten.Monitor().DeviceScaleFactor()
+	if s <= 0 || math.IsNaN(s) {
+		return 1
+	}
+	return s
+}
+
+// updateDeviceScale はモニターのデバイススケールが変わっていれば、描画用のフェイスを作り直す。
+// ウィンドウを別のモニターに移した場合にも追従する。
+func (gm *Game) updateDeviceScale() error {
+	return gm.setDeviceScale(currentDeviceScale())
+}
+
+// setDeviceScale はデバイススケールを s にし、描画用のフェイスを s 倍の解像度で作り直す。
+func (gm *Game) setDeviceScale(s float64) error {
+	if s == gm.deviceScale {
+		return nil
+	}
+	face, err := newFontFaces(gm.fonts, gm.layoutCfg.FontSize, s)
+	if err != nil {
+		return err
+	}
+	codeFace, err := loadCodeFace(gm.layoutCfg.FontSize, s, face)
+	if err != nil {
+		return err
+	}
+	if gm.cjkFonts != nil {
+		cjkFace, err := newFontFaces(gm.cjkFonts, gm.layoutCfg.FontSize, s)
+		if err != nil {
+			return err
+		}
+		gm.drawCJKFace = cjkFace
+	}
+	if gm.boldFonts != nil {
+		boldFace, err := newFontFaces(gm.boldFonts, gm.layoutCfg.FontSize, s)
+		if err != nil {
+			return err
+		}
+		gm.drawBoldFace = boldFace
+	}
+	gm.deviceScale = s
+	gm.drawFace = face
+	gm.drawCodeFace = codeFace
+	return nil
+}
+
+// drawRunFace はスタイルに応じた描画用のフォントを返す。計測には runFace を使う。
+func (gm *Game) drawRunFace(style textStyle) text.Face {
+	if style&textCode != 0 {
+		return gm.drawCodeFace
+	}
+	if style&textCJK != 0 && gm.drawCJKFace != nil {
+		return gm.drawCJKFace
+	}
+	if style&textBold != 0 && gm.drawBoldFace != nil {
+		return gm.drawBoldFace
+	}
+	return gm.drawFace
+}
+
+// physicalSize は論理ピクセルのサイズを描画先の画面のピクセル数に変換する。
+func (gm *Game) physicalSize(w, h int) (int, int) {
+	return int(math.Ceil(float64(w) * gm.deviceScale)), int(math.Ceil(float64(h) * gm.deviceScale))
+}
+
+// cursorPosition はカーソルの位置を論理座標で返す。
+func (gm *Game) cursorPosition() (int, int) {
+	x, y := ebiten.CursorPosition()
+	return int(float64(x) / gm.deviceScale), int(float64(y) / gm.deviceScale)
+}
+
+// scalePath は論理座標のパスを s 倍した、描画先の画面の座標のパスを返す。
+func scalePath(p *vector.Path, s float32) *vector.Path {
+	op := &vector.AddPathOptions{}
+	op.GeoM.Scale(float64(s), float64(s))
+	var sp vector.Path
+	sp.AddPath(p, op)
+	return &sp
+}
@@ -0,0 +1,31 @@
+package mascot
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestSetDeviceScale(t *testing.T) {
+	for _, s := range []float64{1, 1.5, 2} {
+		t.Run(fmt.Sprint(s), func(t *testing.T) {
+			gm := newTestGame(t, DefaultOptions())
+			gm.deviceScale = 0 // 必ずフェイスを作り直させる
+			if err := gm.setDeviceScale(s); err != nil {
+				t.Fatal(err)
+			}
+			if gm.deviceScale != s {
+				t.Errorf("deviceScale = %v, want %v", gm.deviceScale, s)
+			}
+			// ヒンティングで高さは整数のピクセルに丸められるため、1px の差を許す
+			want := gm.fontFace.Metrics().HAscent * s
+			if got := gm.drawFace.Metrics().HAscent; math.Abs(got-want) > 1 {
+				t.Errorf("drawFace ascent = %v, want about %v", got, want)
+			}
+			want = gm.codeFace.Metrics().HAscent * s
+			if got := gm.drawCodeFace.Metrics().HAscent; math.Abs(got-want) > 1 {
+				t.Errorf("drawCodeFace ascent = %v, want about %v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package mascot
+
+import (
+	"math"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// textStyle は文字ごとの強調と文字色（ANSI の SGR による指定）。
+type textStyle uint16
+
+const (
+	textBold   textStyle = 1 << iota // *太字*
+	textItalic                       // _斜体_
+	textCode                         // コードブロック（等幅フォント）
+	textLink                         // URL（下線付きでクリックするとブラウザで開く）
+	textCJK                          // CJK のメッセージの文字（CJK 用のフォントで描画する）
+)
+
+// 強調の描画パラメータ。斜体と、太字のフォントがない場合の太字は通常の書体から合成する。
+const (
+	boldOffset = 1    // 太字を合成する場合に重ねて描画するずらし幅(px)
+	italicSkew = -0.2 // 斜体の傾き（ベースラインを基準にしたせん断）
+
+	underlineOffset = 2 // リンクの下線のベースラインからの距離(px)
+)
+
+// outlineDirs は文字の縁取りを描く8方向の単位ベクトル。
+var outlineDirs = [8][2]float64{
+	{-1, 0}, {1, 0}, {0, -1}, {0, 1},
+	{-math.Sqrt2 / 2, -math.Sqrt2 / 2}, {math.Sqrt2 / 2, -math.Sqrt2 / 2},
+	{-math.Sqrt2 / 2, math.Sqrt2 / 2}, {math.Sqrt2 / 2, math.Sqrt2 / 2},
+}
+
+// escapeMark は直後の記号を記法ではなく文字として表示するためのエスケープ。
+const escapeMark = '\\'
+
+// emphasisMarks は記法の記号と、その記号で囲んだ文字に付けるスタイル。
+var emphasisMarks = map[rune]textStyle{
+	'*': textBold,
+	'_': textItalic,
+}
+
+// parseEmphasis は *太字* と _斜体_ の記法を取り除き、表示するテキストと各文字のスタイルを返す。
+// 記号の前に \ を置くと記号をそのまま表示する。閉じる記号がない場合や、
+// 単語の途中にある記号（snake_case など）は記法とみなさない。記法は行をまたがない。
+// base は s の各文字にあらかじめ付いているスタイルで、強調と合わせて返す。
+func parseEmphasis(s string, base []textStyle) (string, []textStyle) {
+	runes := []rune(s)
+
+	// 対になる記号の位置を求める
+	paired := make([]bool, len(runes))
+	escaped := make([]bool, len(runes))
+	open := map[rune]int{}
+	for i, r := range runes {
+		if r == '\n' {
+			clear(open)
+			continue
+		}
+		if r == escapeMark && i+1 < len(runes) && emphasisMarks[runes[i+1]] != 0 {
+			escaped[i+1] = true
+			continue
+		}
+		if emphasisMarks[r] == 0 || escaped[i] {
+			continue
+		}
+		if j, ok := open[r]; ok && canCloseEmphasis(runes, i) {
+			paired[j], paired[i] = true, true
+			delete(open, r)
+		} else if canOpenEmphasis(runes, i) {
+			open[r] = i
+		}
+	}
+
+	// 記号とエスケープの \ を取り除き、囲まれた文字にスタイルを付ける
+	out := make([]rune, 0, len(runes))
+	styles := make([]textStyle, 0, len(runes))
+	var cur textStyle
+	for i, r := range runes {
+		switch {
+		case r == '\n':
+			cur = 0
+		case paired[i]:
+			cur ^= emphasisMarks[r]
+			continue
+		case i+1 < len(runes) && escaped[i+1] && r == escapeMark:
+			continue
+		}
+		out = append(out, r)
+		styles = append(styles, cur|styleAt(base, i))
+	}
+	return string(out), styles
+}
+
+// canOpenEmphasis は runes[i] の記号が強調の始まりになれるかどうかを返す。
+func canOpenEmphasis(runes []rune, i int) bool {
+	if i+1 >= len(runes) || unicode.IsSpace(runes[i+1]) {
+		return false
+	}
+	return i == 0 || !isLetterOrDigit(runes[i-1])
+}
+
+// canCloseEmphasis は runes[i] の記号が強調の終わりになれるかどうかを返す。
+func canCloseEmphasis(runes []rune, i int) bool {
+	if i == 0 || unicode.IsSpace(runes[i-1]) {
+		return false
+	}
+	return i+1 >= len(runes) || !isLetterOrDigit(runes[i+1])
+}
+
+// isLetterOrDigit は単語を構成する文字かどうかを返す。
+func isLetterOrDigit(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// splitStyles は折り返し前のテキストの各文字のスタイルを、折り返し後の各行に対応付ける。
+// wrapText は改行を挿入し、折り返し直後の空白を取り除くだけなので、先頭から順に照合できる。
+func splitStyles(plain string, styles []textStyle, wrapped []string) [][]textStyle {
+	src := []rune(plain)
+	i := 0
+	result := make([][]textStyle, len(wrapped))
+	for n, line := range wrapped {
+		if n > 0 && i < len(src) && src[i] == '\n' {
+			i++
+		}
+		for _, r := range line {
+			// 折り返しで取り除かれた空白を読み飛ばす
+			for i < len(src) && src[i] != r && unicode.IsSpace(src[i]) && src[i] != '\n' {
+				i++
+			}
+			var st textStyle
+			if i < len(src) && src[i] == r {
+				st = styles[i]
+				i++
+			}
+			result[n] = append(result[n], st)
+		}
+	}
+	return result
+}
+
+// styledRun は同じスタイルが続く文字の並び。
+type styledRun struct {
+	text  string
+	style textStyle
+}
+
+// styledRuns は1行の文字をスタイルごとの並びに分ける。styles が足りない文字はスタイルなしとして扱う。
+func styledRuns(runes []rune, styles []textStyle) []styledRun {
+	var runs []styledRun
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i < len(runes) && styleAt(styles, i) == styleAt(styles, start) {
+			continue
+		}
+		runs = append(runs, styledRun{text: string(runes[start:i]), style: styleAt(styles, start)})
+		start = i
+	}
+	return runs
+}
+
+// styleAt は i 番目の文字のスタイルを返す。
+func styleAt(styles []textStyle, i int) textStyle {
+	if i < len(styles) {
+		return styles[i]
+	}
+	return 0
+}
+
+// runFace はスタイルに応じたフォントを返す。
+func (gm *Game) runFace(style textStyle) text.Face {
+	if style&textCode != 0 {
+		return gm.codeFace
+	}
+	if style&textCJK != 0 && gm.cjkFace != nil {
+		return gm.cjkFace
+	}
+	if style&textBold != 0 && gm.boldFace != nil {
+		return gm.boldFace
+	}
+	return gm.fontFace
+}
+
+// syntheticBold は style の文字を、太字のフォントではなく通常の書体の重ね描きで太字にするかどうかを返す。
+func (gm *Game) syntheticBold(style textStyle) bool {
+	return style&textBold != 0 && gm.runFace(style) != gm.boldFace
+}
+
+// markCJK はコードブロック以外の文字に textCJK を付ける。
+func markCJK(styles []textStyle) {
+	for i, st := range styles {
+		if st&textCode == 0 {
+			styles[i] = st | textCJK
+		}
+	}
+}
+
+// measureRuns はスタイル付きの並びを描画した際の幅(px)を返す。
+func (gm *Game) measureRuns(runs []styledRun) float64 {
+	var w float64
+	for _, run := range runs {
+		w += measureText(gm.runFace(run.style), run.text)
+		if gm.syntheticBold(run.style) {
+			w += boldOffset
+		}
+	}
+	return w
+}
+
+// drawRuns はスタイル付きの並びを論理座標の (x, y) から描画する。y は行の上端。
+// 太字は太字のフォントで描き、なければ少しずらして重ね描きする。斜体はベースラインを基準に傾けて描画する。リンクは linkColor で下線を引く。
+// 縁取りの設定があれば、先にすべての並びを outlineDirs の向きにずらして縁取りの色で描き、その上に文字を描く。
+func (gm *Game) drawRuns(dst *ebiten.Image, runs []styledRun, x, y float64, clr ebiten.ColorScale) {
+	if gm.outlineWidth > 0 {
+		// 隣の並びの文字に縁取りが重ならないよう、縁取りは行全体で先に描く
+		oc := colorScale(gm.outlineColor)
+		ox := x
+		for _, run := range runs {
+			for _, d := range outlineDirs {
+				gm.drawRunText(dst, run, ox+d[0]*gm.outlineWidth, y+d[1]*gm.outlineWidth, oc)
+			}
+			ox += gm.measureRuns([]styledRun{run})
+		}
+	}
+
+	s := gm.deviceScale
+	for _, run := range runs {
+		runClr := clr
+		if c, ok := run.style.ansiColor(); ok {
+			runClr = colorScale(c)
+		}
+		if run.style&textLink != 0 {
+			runClr = colorScale(linkColor)
+			w := gm.measureRuns([]styledRun{run})
+			baseline := y + gm.runFace(run.style).Metrics().HAscent
+			vector.FillRect(dst, float32(x*s), float32((baseline+underlineOffset)*s), float32(w*s), float32(s), linkColor, false)
+		}
+		gm.drawRunText(dst, run, x, y, runClr)
+		x += gm.measureRuns([]styledRun{run})
+	}
+}
+
+// drawRunText は1つの並びの文字を論理座標の (x, y) から clr で描画する。y は行の上端。
+func (gm *Game) drawRunText(dst *ebiten.Image, run styledRun, x, y float64, clr ebiten.ColorScale) {
+	s := gm.deviceScale
+	face := gm.drawRunFace(run.style)
+	ascent := face.Metrics().HAscent
+	passes := 1
+	if gm.syntheticBold(run.style) {
+		passes = 2
+	}
+	for p := range passes {
+		op := &text.DrawOptions{}
+		if run.style&textItalic != 0 {
+			op.GeoM.Translate(0, -ascent)
+			op.GeoM.Skew(italicSkew, 0)
+			op.GeoM.Translate(0, ascent)
+		}
+		op.GeoM.Translate((x+float64(p*boldOffset))*s, y*s)
+		op.ColorScale = clr
+		text.Draw(dst, run.text, face, op)
+	}
+}
@@ -0,0 +1,49 @@
+package mascot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/font/gofont/gobold"
+)
+
+func TestBoldFace(t *testing.T) {
+	bold := []styledRun{{text: "Gopher", style: textBold}}
+	regular := []styledRun{{text: "Gopher"}}
+
+	t.Run("bold font", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "gobold.ttf")
+		if err := os.WriteFile(path, gobold.TTF, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		opts := DefaultOptions()
+		opts.BoldFont = path
+		gm := newTestGame(t, opts)
+		if gm.runFace(textBold) == gm.runFace(0) {
+			t.Error("runFace(textBold) is the regular face, want the bold font")
+		}
+		if gm.drawRunFace(textBold) == gm.drawRunFace(0) {
+			t.Error("drawRunFace(textBold) is the regular face, want the bold font")
+		}
+		if gm.syntheticBold(textBold) {
+			t.Error("syntheticBold(textBold) = true, want false with a bold font")
+		}
+		if got, want := gm.measureRuns(bold), measureText(gm.boldFace, "Gopher"); got != want {
+			t.Errorf("bold width = %v, want %v measured with the bold font", got, want)
+		}
+	})
+
+	t.Run("synthesized", func(t *testing.T) {
+		gm := newTestGame(t, DefaultOptions())
+		if gm.runFace(textBold) != gm.runFace(0) {
+			t.Error("runFace(textBold) differs from the regular face without a bold font")
+		}
+		if !gm.syntheticBold(textBold) {
+			t.Error("syntheticBold(textBold) = false, want true without a bold font")
+		}
+		if got, want := gm.measureRuns(bold), gm.measureRuns(regular)+boldOffset; got != want {
+			t.Errorf("bold width = %v, want %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package mascot
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// defaultBlurFadeSec はフォーカスを失った際に薄くする、または戻す際にかける既定の秒数。
+const defaultBlurFadeSec = 0.3
+
+// focusTarget はフォーカスの状態から、ウィンドウ全体の不透明度に掛ける目標の値を返す。
+// フォーカスがない間は blurOpacity にする。ドラッグ中はフォーカスを取り戻したものとして元に戻す。
+// マウス操作を背後に通す設定ではウィンドウがフォーカスを得られないため、薄くしない。
+func (gm *Game) focusTarget() float64 {
+	if gm.blurOpacity >= 1 || gm.clickThrough ||
+		ebiten.IsFocused() || gm.dragging || gm.bubbleDragging || gm.touching {
+		return 1
+	}
+	return gm.blurOpacity
+}
+
+// updateFocus はウィンドウ全体の不透明度に掛ける値を、フォーカスの状態に応じた目標の値に近づける。
+// blurFadeSec が0なら即座に切り替える。
+func (gm *Game) updateFocus() {
+	target := gm.focusTarget()
+	if gm.focusDim == target {
+		return
+	}
+	gm.dirty = true
+	frames := gm.blurFadeSec * float64(ebiten.TPS())
+	if frames < 1 {
+		gm.focusDim = target
+		return
+	}
+	step := (1 - gm.blurOpacity) / frames
+	if gm.focusDim < target {
+		gm.focusDim = min(gm.focusDim+step, target)
+	} else {
+		gm.focusDim = max(gm.focusDim-step, target)
+	}
+}
@@ -0,0 +1,34 @@
+package mascot
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestUpdateFocusFade(t *testing.T) {
+	// マウス操作を背後に通す設定ではフォーカスの有無によらず薄くしないため、目標は常に 1 になる
+	gm := &Game{blurOpacity: 0.4, blurFadeSec: 0.5, clickThrough: true, focusDim: 0.4}
+	frames := int(math.Ceil(gm.blurFadeSec * float64(ebiten.TPS())))
+	gm.updateFocus()
+	if gm.focusDim <= 0.4 || gm.focusDim >= 1 {
+		t.Errorf("focusDim after one frame = %v, want between 0.4 and 1", gm.focusDim)
+	}
+	if !gm.dirty {
+		t.Error("updateFocus did not mark the screen dirty")
+	}
+	for range frames {
+		gm.updateFocus()
+	}
+	if gm.focusDim != 1 {
+		t.Errorf("focusDim after %d frames = %v, want 1", frames+1, gm.focusDim)
+	}
+
+	// 0 秒なら即座に切り替える
+	gm = &Game{blurOpacity: 0.4, clickThrough: true, focusDim: 0.4}
+	gm.updateFocus()
+	if gm.focusDim != 1 {
+		t.Errorf("focusDim without a fade = %v, want 1", gm.focusDim)
+	}
+}
@@ -0,0 +1,141 @@
+package mascot
+
+import (
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	historyGap            = 8   // 履歴の吹き出しどうし、および現在の吹き出しとの間隔(px)
+	defaultHistoryFalloff = 0.6 // 履歴が1件古くなるごとに掛ける既定の不透明度
+)
+
+// historyEntry は履歴として表示する過去のメッセージ。
+type historyEntry struct {
+	text  string  // 表示する1行（複数行のメッセージは1行目に省略記号を付ける）
+	width float64 // text の描画幅(px)
+	timer int     // 履歴から消えるまでの残りフレーム数。0 なら押し出されるまで残す
+}
+
+// historyBox は履歴の吹き出しの位置とサイズ。
+type historyBox struct {
+	x, y, w, h float32
+}
+
+// archiveMessage は表示中のメッセージを履歴の先頭に加える。履歴の件数を超えた古いものは取り除く。
+// 履歴は、現在のメッセージと同じ表示時間が過ぎると消える。
+func (gm *Game) archiveMessage() {
+	if gm.historyLen <= 0 || !gm.hasMessage || gm.messageText == "" || gm.browseIndex >= 0 || gm.noticing {
+		return
+	}
+	line, rest, multi := strings.Cut(gm.messageText, "\n")
+	if multi && strings.TrimSpace(rest) != "" {
+		line += ellipsis
+	}
+	line = truncateText(gm.fontFace, line, gm.maxLineWidth)
+	e := historyEntry{
+		text:  line,
+		width: measureText(gm.fontFace, line),
+		timer: gm.duration.frames(gm.messageText),
+	}
+	gm.history = append([]historyEntry{e}, gm.history...)
+	if len(gm.history) > gm.historyLen {
+		gm.history = gm.history[:gm.historyLen]
+	}
+}
+
+// updateHistory は履歴の表示時間を進め、過ぎたものを取り除いてレイアウトを計算し直す。
+func (gm *Game) updateHistory() {
+	n := 0
+	for _, e := range gm.history {
+		if e.timer > 0 {
+			e.timer--
+			if e.timer == 0 {
+				continue
+			}
+		}
+		gm.history[n] = e
+		n++
+	}
+	if n == len(gm.history) {
+		return
+	}
+	clear(gm.history[n:])
+	gm.history = gm.history[:n]
+	message := ""
+	if gm.hasMessage {
+		message = strings.Join(gm.layout.lines, "\n")
+	}
+	gm.relayout(message, gm.layout.bubbleStyle)
+}
+
+// historyWidths はレイアウト計算に渡す履歴のテキストの描画幅を新しい順に返す。
+func (gm *Game) historyWidths() []float64 {
+	ws := make([]float64, len(gm.history))
+	for i, e := range gm.history {
+		ws[i] = e.width
+	}
+	return ws
+}
+
+// historyAlpha は i 番目（0 が最新）の履歴の不透明度を返す。
+// 古いものほど historyFalloff 倍ずつ薄くし、消える直前の fadeSec の間はフェードアウトする。
+func (gm *Game) historyAlpha(i int) float64 {
+	a := math.Pow(gm.historyFalloff, float64(i+1))
+	fadeFrames := gm.fadeSec * float64(ebiten.TPS())
+	if t := gm.history[i].timer; t > 0 && fadeFrames >= 1 {
+		a *= math.Min(float64(t)/fadeFrames, 1)
+	}
+	return a
+}
+
+// historyFading はフェードアウト中の履歴があるかを返す。
+func (gm *Game) historyFading() bool {
+	fadeFrames := gm.fadeSec * float64(ebiten.TPS())
+	for _, e := range gm.history {
+		if e.timer > 0 && float64(e.timer) < fadeFrames {
+			return true
+		}
+	}
+	return false
+}
+
+// drawHistory は履歴の吹き出しを描画する。しっぽは付けない。
+func (gm *Game) drawHistory(screen *ebiten.Image, ly layout) {
+	if len(ly.history) == 0 {
+		return
+	}
+	s := float32(gm.deviceScale)
+	m := gm.fontFace.Metrics()
+	for i, box := range ly.history {
+		if i >= len(gm.history) {
+			break
+		}
+		// 塗りと枠線の重なりが透けないよう、一度不透明で描画してから不透明度を掛けて合成する
+		layer := ensureLayer(&gm.historyLayer, screen.Bounds().Dx(), screen.Bounds().Dy())
+		layer.Clear()
+
+		r := float32(math.Min(gm.layoutCfg.BubbleRadius, float64(box.h)/2))
+		bp := gm.bubblePath(box.x, box.y, box.w, box.h, r)
+		vector.FillPath(layer, scalePath(bp, s), nil, &vector.DrawPathOptions{
+			AntiAlias: gm.antiAlias, ColorScale: colorScale(gm.bubbleFill),
+		})
+		stroke := gm.strokeStyle.options(float32(gm.layoutCfg.StrokeWidth)*s, vector.LineCapButt, vector.LineJoinMiter)
+		vector.StrokePath(layer, scalePath(bp, s), stroke, &vector.DrawPathOptions{
+			AntiAlias: gm.antiAlias, ColorScale: colorScale(gm.bubbleStroke),
+		})
+
+		e := gm.history[i]
+		x := float64(box.x) + (float64(box.w)-e.width)/2
+		y := float64(box.y) + (float64(box.h)-m.CapHeight-m.HDescent)/2 + m.CapHeight - m.HAscent
+		gm.drawRuns(layer, []styledRun{{text: e.text}}, x, y, colorScale(color.Black))
+
+		op := &ebiten.DrawImageOptions{}
+		op.ColorScale.ScaleAlpha(float32(gm.historyAlpha(i)))
+		screen.DrawImage(layer, op)
+	}
+}
@@ -0,0 +1,38 @@
+package mascot
+
+import (
+	"image"
+	"slices"
+	"testing"
+)
+
+func TestArchiveMessage(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Input = nil
+	opts.HistoryLen = 2
+	gm := newTestGame(t, opts)
+
+	for _, text := range []string{"one", "two\nlines", "three", "four"} {
+		gm.showMessage(message{Text: text})
+	}
+	var got []string
+	for _, e := range gm.history {
+		got = append(got, e.text)
+	}
+	if want := []string{"three", "two" + ellipsis}; !slices.Equal(got, want) {
+		t.Fatalf("history = %q, want %q", got, want)
+	}
+
+}
+
+func TestCalcLayoutHistory(t *testing.T) {
+	cfg := DefaultLayoutConfig()
+	ly, _, _ := calcLayout(image.Pt(100, 100), 80, "hello", false, []float64{40, 60}, cornerBottomRight, false, cfg)
+	if len(ly.history) != 2 {
+		t.Fatalf("layout has %d history boxes, want 2", len(ly.history))
+	}
+	// 履歴の吹き出しは新しい順に現在の吹き出しの上へ積まれる
+	if !(ly.history[1].y+ly.history[1].h < ly.history[0].y && ly.history[0].y+ly.history[0].h < ly.bubbleY) {
+		t.Errorf("history boxes %+v are not stacked above the bubble at y=%v", ly.history, ly.bubbleY)
+	}
+}
@@ -0,0 +1,60 @@
+package mascot
+
+import (
+	_ "embed"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// defaultIdlePhrases は待機中のひとことの既定の一覧。1行に1つ書く。
+//
+//go:embed assets/idle.txt
+var defaultIdlePhrases string
+
+// loadIdlePhrases は待機中のひとことの一覧を読み込む。
+// path が空または読み込めない場合は埋め込みの一覧を使う。空行は無視する。
+func loadIdlePhrases(path string) []string {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			if phrases := splitPhrases(string(data)); len(phrases) > 0 {
+				return phrases
+			}
+			err = fmt.Errorf("no phrases in %s", path)
+		}
+		fmt.Fprintf(os.Stderr, "read idle phrases: %v; using the default phrases\n", err)
+	}
+	return splitPhrases(defaultIdlePhrases)
+}
+
+// splitPhrases は1行に1つ書かれたひとことを分割する。前後の空白と空行は取り除く。
+func splitPhrases(s string) []string {
+	var phrases []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			phrases = append(phrases, line)
+		}
+	}
+	return phrases
+}
+
+// updateIdle はメッセージのない時間を数え、idleSec 秒続いたら一覧からランダムに選んだひとことを話す。
+// メッセージの表示中や待ち行列にメッセージがある間は数え直す。
+func (gm *Game) updateIdle() {
+	if gm.idleSec <= 0 || len(gm.idlePhrases) == 0 {
+		return
+	}
+	if gm.hasMessage || gm.queueLen() > 0 {
+		gm.idleTimer = 0
+		return
+	}
+	gm.idleTimer++
+	if float64(gm.idleTimer) >= gm.idleSec*float64(ebiten.TPS()) {
+		gm.idleTimer = 0
+		gm.Say(gm.idlePhrases[rand.IntN(len(gm.idlePhrases))])
+	}
+}
@@ -0,0 +1,48 @@
+package mascot
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestUpdateIdle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idle.txt")
+	if err := os.WriteFile(path, []byte("hello\n\n  bye  \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opts := DefaultOptions()
+	opts.Input = nil
+	opts.IdleSec = 1
+	opts.IdleFile = path
+	gm := newTestGame(t, opts)
+	if want := []string{"hello", "bye"}; !slices.Equal(gm.idlePhrases, want) {
+		t.Fatalf("idlePhrases = %q, want %q", gm.idlePhrases, want)
+	}
+
+	// メッセージが届くと数え直す
+	tps := ebiten.TPS()
+	for range tps - 1 {
+		gm.updateIdle()
+	}
+	gm.Say("real")
+	gm.updateIdle()
+	if got := queuedTexts(gm); !slices.Equal(got, []string{"real"}) {
+		t.Fatalf("queued %q, want only the real message", got)
+	}
+
+	for range tps - 1 {
+		gm.updateIdle()
+	}
+	if n := gm.queueLen(); n != 0 {
+		t.Fatalf("queued %d messages before %d frames without messages", n, tps)
+	}
+	gm.updateIdle()
+	got := queuedTexts(gm)
+	if len(got) != 1 || !slices.Contains(gm.idlePhrases, got[0]) {
+		t.Errorf("queued %q after %d frames without messages, want one of %q", got, tps, gm.idlePhrases)
+	}
+}
@@ -0,0 +1,57 @@
+package mascot
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// imageExts は名前付きのGopher画像として読み込むファイルの拡張子。
+var imageExts = map[string]bool{".png": true, ".jpg": true, ".jpeg": true, ".gif": true}
+
+// loadNamedImages は dir にある画像（PNG・JPEG・GIF）を、拡張子を除いたファイル名をキーにして読み込む。
+// dir が空なら nil を返す。読み込めない画像はエラーを表示して読み飛ばす。
+func loadNamedImages(dir string) (map[string][]gopherFrame, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read image dir: %w", err)
+	}
+	images := map[string][]gopherFrame{}
+	for _, e := range entries {
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		if e.IsDir() || !imageExts[ext] {
+			continue
+		}
+		frames, err := loadGopherImageFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v; skipping\n", err)
+			continue
+		}
+		images[strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))] = frames
+	}
+	return images, nil
+}
+
+// drawMessageImage はメッセージで指定された画像を、Gopherの矩形に収まるよう縦横比を保って縮小・拡大し、
+// 下端・左右中央を揃えて描画する。ウィンドウのレイアウトは通常のGopher画像のまま変えない。
+// アニメーションGIFは最初のフレームだけを描画する。
+func (gm *Game) drawMessageImage(screen *ebiten.Image, ly layout) {
+	img := gm.messageImage[0].image
+	w := float64(gm.gopherImage.Bounds().Dx()) * ly.gopherScale
+	h := float64(gm.gopherImage.Bounds().Dy()) * ly.gopherScale
+	iw, ih := float64(img.Bounds().Dx()), float64(img.Bounds().Dy())
+	scale := math.Min(w/iw, h/ih)
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(ly.gopherX+(w-iw*scale)/2, ly.gopherY+h-ih*scale)
+	op.GeoM.Scale(gm.deviceScale, gm.deviceScale)
+	screen.DrawImage(img, op)
+}
@@ -0,0 +1,43 @@
+package mascot
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadNamedImages(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string][]byte{
+		"angry.png":  gopherPNG,
+		"happy.PNG":  gopherPNG,
+		"broken.png": []byte("not an image"),
+		"notes.txt":  []byte("hello"),
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	images, err := loadNamedImages(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := slices.Sorted(maps.Keys(images)), []string{"angry", "happy"}; !slices.Equal(got, want) {
+		t.Fatalf("loaded images %q, want %q", got, want)
+	}
+
+	opts := DefaultOptions()
+	opts.Input = nil
+	opts.ImageDir = dir
+	gm := newTestGame(t, opts)
+	gm.showMessage(message{Text: "grr", Image: "angry"})
+	if gm.messageImage == nil {
+		t.Error("messageImage = nil after a message with a known image")
+	}
+	gm.showMessage(message{Text: "hm", Image: "unknown"})
+	if gm.messageImage != nil {
+		t.Error("messageImage is set after a message with an unknown image")
+	}
+}
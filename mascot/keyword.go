@@ -0,0 +1,41 @@
+package mascot
+
+import (
+	"fmt"
+	"strings"
+)
+
+// keywordReaction はメッセージに含まれるキーワードと、そのときに切り替える表情。
+type keywordReaction struct {
+	keyword    string // 小文字にしたキーワード
+	expression string
+}
+
+// parseKeywords は "キーワード=表情名,キーワード=表情名" 形式のキーワードの対応を解析する。
+// 空文字列なら nil を返す。書いた順に照合する。
+func parseKeywords(s string) ([]keywordReaction, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var reactions []keywordReaction
+	for _, pair := range strings.Split(s, ",") {
+		keyword, expression, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || keyword == "" || expression == "" {
+			return nil, fmt.Errorf("invalid keyword mapping %q", pair)
+		}
+		reactions = append(reactions, keywordReaction{keyword: strings.ToLower(keyword), expression: expression})
+	}
+	return reactions, nil
+}
+
+// messageExpression はメッセージを表示する間の表情を返す。大文字・小文字を区別せずにキーワードを探し、
+// 最初に見つかったキーワードの表情を返す。見つからなければ talking を返す。
+func (gm *Game) messageExpression(text string) string {
+	lower := strings.ToLower(text)
+	for _, r := range gm.keywords {
+		if strings.Contains(lower, r.keyword) {
+			return r.expression
+		}
+	}
+	return expressionTalking
+}
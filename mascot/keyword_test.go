@@ -0,0 +1,24 @@
+package mascot
+
+import "testing"
+
+func TestMessageExpression(t *testing.T) {
+	keywords, err := parseKeywords("error=angry, done=happy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gm := &Game{keywords: keywords}
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"build error", "angry"},
+		{"Build ERROR in main.go", "angry"},
+		{"hello", expressionTalking},
+	}
+	for _, tt := range tests {
+		if got := gm.messageExpression(tt.text); got != tt.want {
+			t.Errorf("messageExpression(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
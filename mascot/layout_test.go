@@ -0,0 +1,268 @@
+package mascot
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"testing"
+)
+
+// layoutWant は calcLayout の結果のうち、テストで確かめる寸法。
+type layoutWant struct {
+	sw, sh           int
+	gopherX, gopherY float64
+	bubbleX, bubbleY float32
+	bubbleW, bubbleH float32
+}
+
+func TestCalcLayout(t *testing.T) {
+	base := DefaultLayoutConfig()
+	base.MaxGopherPx = 100
+	gopher := image.Pt(100, 100)
+
+	tests := []struct {
+		name    string
+		message string
+		textW   float64
+		c       corner
+		below   bool
+		cfg     func(*LayoutConfig)
+		want    layoutWant
+	}{
+		{
+			name:    "bottom right",
+			message: "hello",
+			textW:   200,
+			c:       cornerBottomRight,
+			want:    layoutWant{sw: 324, sh: 300, gopherX: 204, gopherY: 195, bubbleX: 40, bubbleY: 114, bubbleW: 244, bubbleH: 56},
+		},
+		{
+			name:    "bottom left",
+			message: "hello",
+			textW:   200,
+			c:       cornerBottomLeft,
+			want:    layoutWant{sw: 324, sh: 300, gopherX: 20, gopherY: 195, bubbleX: 40, bubbleY: 114, bubbleW: 244, bubbleH: 56},
+		},
+		{
+			name:    "bubble below",
+			message: "hello",
+			textW:   200,
+			c:       cornerBottomRight,
+			below:   true,
+			want:    layoutWant{sw: 324, sh: 300, gopherX: 204, gopherY: 20, bubbleX: 40, bubbleY: 145, bubbleW: 244, bubbleH: 56},
+		},
+		{
+			name:    "two lines",
+			message: "hello\nworld",
+			textW:   200,
+			c:       cornerBottomRight,
+			want:    layoutWant{sw: 324, sh: 300, gopherX: 204, gopherY: 195, bubbleX: 40, bubbleY: 86, bubbleW: 244, bubbleH: 84},
+		},
+		{
+			name: "no message",
+			c:    cornerBottomRight,
+			want: layoutWant{sw: 300, sh: 300, gopherX: 180, gopherY: 195, bubbleX: 150, bubbleY: 170},
+		},
+		{
+			name: "no minimum window size",
+			c:    cornerBottomRight,
+			cfg:  func(cfg *LayoutConfig) { cfg.MinWindowSize = 0 },
+			want: layoutWant{sw: 140, sh: 206, gopherX: 20, gopherY: 101, bubbleX: 70, bubbleY: 76},
+		},
+		{
+			name:    "custom padding and gap",
+			message: "hello",
+			textW:   200,
+			c:       cornerBottomRight,
+			cfg: func(cfg *LayoutConfig) {
+				cfg.BubblePadX = 100
+				cfg.BubblePadY = 60
+				cfg.BubbleGap = 10
+			},
+			want: layoutWant{sw: 380, sh: 300, gopherX: 260, gopherY: 195, bubbleX: 40, bubbleY: 97, bubbleW: 300, bubbleH: 88},
+		},
+		{
+			name:    "custom margins",
+			message: "hello",
+			textW:   200,
+			c:       cornerBottomLeft,
+			cfg: func(cfg *LayoutConfig) {
+				cfg.GopherMarginSide = 50
+				cfg.GopherMarginBottom = 30
+			},
+			want: layoutWant{sw: 324, sh: 300, gopherX: 50, gopherY: 170, bubbleX: 40, bubbleY: 89, bubbleW: 244, bubbleH: 56},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := base
+			if tt.cfg != nil {
+				tt.cfg(&cfg)
+			}
+			ly, sw, sh := calcLayout(gopher, tt.textW, tt.message, false, nil, tt.c, tt.below, cfg)
+			got := layoutWant{
+				sw: sw, sh: sh,
+				gopherX: ly.gopherX, gopherY: ly.gopherY,
+				bubbleX: ly.bubbleX, bubbleY: ly.bubbleY,
+				bubbleW: ly.bubbleW, bubbleH: ly.bubbleH,
+			}
+			if got != tt.want {
+				t.Errorf("calcLayout() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalcLayoutBubbleShape(t *testing.T) {
+	tests := []struct {
+		name       string
+		radius     float64
+		padX, padY float64
+		wantRadius float32
+		wantW      float32
+		wantH      float32
+		wantSW     int
+	}{
+		{name: "square", radius: 0, padX: bubblePadX, padY: bubblePadY, wantRadius: 0, wantW: 244, wantH: 56, wantSW: 340},
+		{name: "large padding", radius: bubbleRadius, padX: 300, padY: 200, wantRadius: bubbleRadius, wantW: 500, wantH: 228, wantSW: 580},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultLayoutConfig()
+			cfg.BubbleRadius = tt.radius
+			cfg.BubblePadX = tt.padX
+			cfg.BubblePadY = tt.padY
+			ly, sw, sh := calcLayout(image.Pt(300, 300), 200, "hello", false, nil, cornerBottomRight, false, cfg)
+			if ly.bubbleRadius != tt.wantRadius || ly.bubbleW != tt.wantW || ly.bubbleH != tt.wantH || sw != tt.wantSW {
+				t.Errorf("radius, w, h, sw = %v, %v, %v, %v, want %v, %v, %v, %v",
+					ly.bubbleRadius, ly.bubbleW, ly.bubbleH, sw, tt.wantRadius, tt.wantW, tt.wantH, tt.wantSW)
+			}
+			// 吹き出しは Gopher に重ならず、ウィンドウに収まる
+			if bottom := float64(ly.bubbleY + ly.bubbleH); bottom > ly.gopherY || ly.bubbleY < 0 {
+				t.Errorf("bubble spans %v..%v, want within 0..%v", ly.bubbleY, bottom, ly.gopherY)
+			}
+			if want := int(300 + cfg.GopherMarginBottom + cfg.BubbleGap + float64(tt.wantH) + 20); sh != want {
+				t.Errorf("sh = %d, want %d", sh, want)
+			}
+		})
+	}
+}
+
+func TestCalcGopherScale(t *testing.T) {
+	tests := []struct {
+		name       string
+		size       image.Point
+		boxW, boxH float64
+		want       float64
+		drawW      float64
+		drawH      float64
+	}{
+		{"square", image.Pt(100, 100), 300, 300, 3, 300, 300},
+		{"wide 3:1", image.Pt(300, 100), 300, 300, 1, 300, 100},
+		{"tall 1:3", image.Pt(100, 300), 300, 300, 1, 100, 300},
+		{"wide 3:1 in a banner box", image.Pt(300, 100), 600, 200, 2, 600, 200},
+		{"tall 1:3 in a banner box", image.Pt(150, 450), 600, 200, 200.0 / 450, 200.0 / 3, 200},
+		{"shrinks a large image", image.Pt(900, 300), 300, 300, 1.0 / 3, 300, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calcGopherScale(tt.size, tt.boxW, tt.boxH)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("calcGopherScale(%v, %v, %v) = %v, want %v", tt.size, tt.boxW, tt.boxH, got, tt.want)
+			}
+			w, h := float64(tt.size.X)*got, float64(tt.size.Y)*got
+			if math.Abs(w-tt.drawW) > 1e-9 || math.Abs(h-tt.drawH) > 1e-9 {
+				t.Errorf("draw size = %vx%v, want %vx%v", w, h, tt.drawW, tt.drawH)
+			}
+		})
+	}
+}
+
+func TestCalcGopherScaleMaxGopherPx(t *testing.T) {
+	size := image.Pt(100, 100)
+	var prevW, prevH int
+	// ウィンドウの最小サイズより大きくなる範囲で比べる
+	for _, px := range []float64{300, 450, 600} {
+		t.Run(fmt.Sprint(px), func(t *testing.T) {
+			cfg := DefaultLayoutConfig()
+			cfg.MaxGopherPx = px
+			// 画像の辺が MaxGopherPx になるよう、大きさに比例して拡大する
+			ly, sw, sh := calcLayout(size, 100, "", false, nil, cornerBottomRight, false, cfg)
+			if want := px / float64(size.X); math.Abs(ly.gopherScale-want) > 1e-9 {
+				t.Errorf("gopherScale = %v, want %v", ly.gopherScale, want)
+			}
+			if sw <= prevW || sh <= prevH {
+				t.Errorf("window size = %dx%d, want larger than %dx%d", sw, sh, prevW, prevH)
+			}
+			prevW, prevH = sw, sh
+		})
+	}
+}
+
+func TestCalcLayoutLineSpacing(t *testing.T) {
+	bubbleH := func(spacing float64) float32 {
+		cfg := DefaultLayoutConfig()
+		cfg.LineSpacing = spacing
+		ly, _, _ := calcLayout(image.Pt(100, 100), 200, "a\nb\nc", false, nil, cornerBottomRight, false, cfg)
+		return ly.bubbleH
+	}
+	// 3行のメッセージでは、行間が2か所で広がる
+	const extra = 10
+	if got, want := bubbleH(lineSpacing+extra), bubbleH(lineSpacing)+2*extra; got != want {
+		t.Errorf("bubbleH = %v, want %v", got, want)
+	}
+}
+
+func TestCalcLayoutFontSize(t *testing.T) {
+	var prev float32
+	for _, size := range []int{12, 16, 24, 32} {
+		cfg := DefaultLayoutConfig()
+		cfg.FontSize = size
+		ly, _, _ := calcLayout(image.Pt(100, 100), 200, "hello\nworld", false, nil, cornerBottomRight, false, cfg)
+		if ly.bubbleH <= prev {
+			t.Errorf("font size %d: bubbleH = %v, want taller than %v for a smaller font", size, ly.bubbleH, prev)
+		}
+		prev = ly.bubbleH
+	}
+}
+
+func TestCalcLayoutMaxTextHeight(t *testing.T) {
+	message := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10"
+	cfg := DefaultLayoutConfig()
+	cfg.MaxTextHeight = 0
+	free, _, freeH := calcLayout(image.Pt(100, 100), 100, message, false, nil, cornerBottomRight, false, cfg)
+	cfg.MaxTextHeight = 60
+	capped, _, cappedH := calcLayout(image.Pt(100, 100), 100, message, false, nil, cornerBottomRight, false, cfg)
+
+	if capped.textViewH != 60 {
+		t.Errorf("textViewH = %v, want 60", capped.textViewH)
+	}
+	if capped.textH != free.textH {
+		t.Errorf("textH = %v, want %v regardless of the cap", capped.textH, free.textH)
+	}
+	if capped.bubbleH >= free.bubbleH || cappedH >= freeH {
+		t.Errorf("bubbleH, window height = %v, %d, want less than the uncapped %v, %d", capped.bubbleH, cappedH, free.bubbleH, freeH)
+	}
+}
+
+func TestNewLayoutMargins(t *testing.T) {
+	gap := func(ly layout) float64 { return ly.gopherY - float64(ly.bubbleY+ly.bubbleH) }
+	base := newTestGame(t, DefaultOptions()).layoutCfg
+	opts := DefaultOptions()
+	opts.BubbleGap += 10
+	opts.MarginBottom += 7
+	opts.MarginSide = -5 // 負の値は0にする
+	cfg := newTestGame(t, opts).layoutCfg
+	if cfg.GopherMarginSide != 0 {
+		t.Errorf("GopherMarginSide = %v, want 0 for a negative margin", cfg.GopherMarginSide)
+	}
+
+	ly0, _, sh0 := calcLayout(image.Pt(100, 100), 80, "hello", false, nil, cornerBottomRight, false, base)
+	ly1, _, sh1 := calcLayout(image.Pt(100, 100), 80, "hello", false, nil, cornerBottomRight, false, cfg)
+	if d := sh1 - sh0; d != 17 {
+		t.Errorf("window height grew by %d, want 17", d)
+	}
+	if d := gap(ly1) - gap(ly0); math.Abs(d-10) > 0.01 {
+		t.Errorf("gap between the bubble and the gopher grew by %v, want 10", d)
+	}
+}
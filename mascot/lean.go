@@ -0,0 +1,36 @@
+package mascot
+
+import "math"
+
+const (
+	leanMaxPx    = 3   // カーソルの方へ寄せるGopherの描画位置のずれの最大(px)
+	leanRadius   = 200 // Gopherの中心からこの距離(px)以内にカーソルがあるときだけ寄せる
+	leanDeadZone = 40  // 中心からこの距離(px)までは、距離に比例してずれを小さくする
+)
+
+// leanOffset はGopherの中心からカーソルへのベクトル (vx, vy) から、Gopherを寄せる量を返す。
+// 向きはカーソルの方で、大きさは leanMaxPx までに抑える。中心付近では距離に比例して小さくし、
+// leanRadius より遠い場合は寄せない。
+func leanOffset(vx, vy float64) (float64, float64) {
+	d := math.Hypot(vx, vy)
+	if d == 0 || d > leanRadius {
+		return 0, 0
+	}
+	m := leanMaxPx * min(d/leanDeadZone, 1)
+	return vx / d * m, vy / d * m
+}
+
+// updateLean は、カーソルを見る設定でカーソルがGopherの近くにあれば、Gopherを描画する位置のずれを計算する。
+// ずれは描画だけに使い、ドラッグなどの当たり判定はレイアウトの位置のまま行う。ドラッグ中は寄せない。
+func (gm *Game) updateLean(cx, cy int) {
+	var lx, ly float64
+	if gm.lookAtCursor && !gm.dragging {
+		r := gm.gopherRect()
+		c := r.Min.Add(r.Max).Div(2)
+		lx, ly = leanOffset(float64(cx-c.X), float64(cy-c.Y))
+	}
+	if lx != gm.leanX || ly != gm.leanY {
+		gm.leanX, gm.leanY = lx, ly
+		gm.dirty = true
+	}
+}
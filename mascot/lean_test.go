@@ -0,0 +1,35 @@
+package mascot
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLeanOffset(t *testing.T) {
+	tests := []struct {
+		name         string
+		vx, vy       float64
+		wantX, wantY float64
+	}{
+		{"at the center", 0, 0, 0, 0},
+		{"right, full lean", 100, 0, leanMaxPx, 0},
+		{"up, full lean", 0, -100, 0, -leanMaxPx},
+		{"diagonal keeps the direction", 60, 80, leanMaxPx * 0.6, leanMaxPx * 0.8},
+		{"inside the dead zone", leanDeadZone / 2, 0, leanMaxPx / 2.0, 0},
+		{"at the dead zone edge", 0, leanDeadZone, 0, leanMaxPx},
+		{"at the radius", leanRadius, 0, leanMaxPx, 0},
+		{"beyond the radius", leanRadius + 1, 0, 0, 0},
+		{"far diagonal", 300, 300, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := leanOffset(tt.vx, tt.vy)
+			if math.Abs(x-tt.wantX) > 1e-9 || math.Abs(y-tt.wantY) > 1e-9 {
+				t.Errorf("leanOffset(%v, %v) = (%v, %v), want (%v, %v)", tt.vx, tt.vy, x, y, tt.wantX, tt.wantY)
+			}
+			if d := math.Hypot(x, y); d > leanMaxPx+1e-9 {
+				t.Errorf("leanOffset(%v, %v) moves %v px, want at most %v", tt.vx, tt.vy, d, leanMaxPx)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package mascot
+
+import (
+	"fmt"
+	"image/color"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"unicode/utf8"
+)
+
+// urlPattern はメッセージ中の URL に一致する。文末の句読点や閉じ括弧は URL に含めない。
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"]*[^\s<>".,;:!?')\]]`)
+
+// linkColor はリンクの文字色。
+var linkColor = color.NRGBA{R: 0x06, G: 0x45, B: 0xad, A: 0xff}
+
+// markLinks は parseMarkup で解釈したテキストから URL を探し、その文字に textLink を付ける。
+// コードブロックの中の URL はリンクにしない。見つけた URL を出現順に返す。
+func markLinks(plain string, styles []textStyle) []string {
+	var urls []string
+	for _, m := range urlPattern.FindAllStringIndex(plain, -1) {
+		start := utf8.RuneCountInString(plain[:m[0]])
+		n := utf8.RuneCountInString(plain[m[0]:m[1]])
+		if styleAt(styles, start)&textCode != 0 {
+			continue
+		}
+		for i := start; i < start+n && i < len(styles); i++ {
+			styles[i] |= textLink
+		}
+		urls = append(urls, plain[m[0]:m[1]])
+	}
+	return urls
+}
+
+// assignLinks は配置した各行のリンクの並びに、対応する URL を先頭から順に割り当てる。
+// 折り返しで複数の行に分かれた URL は、連続するリンクの並びを合わせて1つの URL とみなす。
+func assignLinks(lines []placedLine, urls []string) {
+	k, acc := 0, 0
+	for i := range lines {
+		pl := &lines[i]
+		pl.urls = make([]string, len(pl.runs))
+		for j, run := range pl.runs {
+			if run.style&textLink == 0 || k >= len(urls) {
+				continue
+			}
+			pl.urls[j] = urls[k]
+			acc += len(run.text)
+			if acc >= len(urls[k]) {
+				k, acc = k+1, 0
+			}
+		}
+	}
+}
+
+// linkAt は論理座標 (x, y) にあるリンクの URL を返す。リンクがなければ空文字列を返す。
+func (gm *Game) linkAt(x, y int) string {
+	view, lines := gm.placeText(gm.layout)
+	fx, fy := float64(x), float64(y)
+	if fy < view.top || fy >= view.bottom {
+		return ""
+	}
+	for _, pl := range lines {
+		if fy < pl.y || fy >= pl.y+gm.layout.lineHeight {
+			continue
+		}
+		rx := pl.x
+		for j, run := range pl.runs {
+			w := gm.measureRuns([]styledRun{run})
+			if pl.urls[j] != "" && fx >= rx && fx < rx+w {
+				return pl.urls[j]
+			}
+			rx += w
+		}
+	}
+	return ""
+}
+
+// openURL は rawURL を OS の既定のブラウザで開く。
+// どの入力元から届いた URL でもコマンドの引数になるため、ホストのある http・https の URL だけを開く。
+func openURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", rawURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("open %s: only http and https URLs can be opened", rawURL)
+	}
+	target := u.String()
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", target)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		cmd = exec.Command("xdg-open", target)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("open %s: %w", target, err)
+	}
+	go cmd.Wait()
+	return nil
+}
@@ -0,0 +1,48 @@
+package mascot
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOpenURLRejectsOtherSchemes(t *testing.T) {
+	for _, rawURL := range []string{
+		"file:///etc/passwd",
+		"javascript:alert(1)",
+		"ms-settings:",
+		"-a /bin/sh",
+		"http://",
+		"https://exa mple.com",
+	} {
+		if err := openURL(rawURL); err == nil {
+			t.Errorf("openURL(%q) = nil, want an error", rawURL)
+		}
+	}
+}
+
+func TestLinkAt(t *testing.T) {
+	gm := newTestGame(t, DefaultOptions())
+	gm.showMessage(parseMessage("see https://go.dev, https://pkg.go.dev."))
+	gm.revealedChars = gm.layout.charCount()
+
+	// 各リンクの並びの中央をクリックすると、その URL になる。リンク以外の文字は空文字列になる
+	_, lines := gm.placeText(gm.layout)
+	var got []string
+	for _, pl := range lines {
+		x := pl.x
+		for j, run := range pl.runs {
+			w := gm.measureRuns([]styledRun{run})
+			url := gm.linkAt(int(x+w/2), int(pl.y+gm.layout.lineHeight/2))
+			if url != pl.urls[j] {
+				t.Errorf("linkAt over %q = %q, want %q", run.text, url, pl.urls[j])
+			}
+			if url != "" {
+				got = append(got, url)
+			}
+			x += w
+		}
+	}
+	if want := []string{"https://go.dev", "https://pkg.go.dev"}; !slices.Equal(got, want) {
+		t.Errorf("links = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+package mascot
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// testGame はメインループの最初の Update の中でテストを実行して終了する ebiten.Game。
+// Game.Update の呼び出しや画像の読み出しはメインループの中でしか行えないため、テストはすべてこの中で実行する。
+type testGame struct {
+	m    *testing.M
+	code int
+}
+
+func (tg *testGame) Update() error {
+	tg.code = tg.m.Run()
+	return ebiten.Termination
+}
+
+func (tg *testGame) Draw(*ebiten.Image) {}
+
+func (tg *testGame) Layout(_, _ int) (int, int) {
+	return 1, 1
+}
+
+func TestMain(m *testing.M) {
+	ebiten.SetWindowSize(1, 1)
+	ebiten.SetWindowDecorated(false)
+	tg := &testGame{m: m}
+	if err := ebiten.RunGameWithOptions(tg, &ebiten.RunGameOptions{
+		InitUnfocused:     true,
+		ScreenTransparent: true,
+		SkipTaskbar:       true,
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(tg.code)
+}
+
+// newTestGame は opts の設定で音を鳴らさない Game を作る。
+func newTestGame(tb testing.TB, opts Options) *Game {
+	tb.Helper()
+	opts.Mute = true
+	gm, err := New(opts)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { gm.Close() })
+	return gm
+}
+
+// updateFrames は gm.Update を n 回呼ぶ。
+func updateFrames(t *testing.T, gm *Game, n int) {
+	t.Helper()
+	for range n {
+		if err := gm.Update(); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+}
+
+// readImage は img の画素を読み出す。
+func readImage(img *ebiten.Image) *image.RGBA {
+	rgba := image.NewRGBA(img.Bounds())
+	img.ReadPixels(rgba.Pix)
+	return rgba
+}
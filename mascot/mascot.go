@@ -0,0 +1,2216 @@
+package mascot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+)
+
+//go:embed assets/gopher.png
+var gopherPNG []byte
+
+//go:embed assets/font.ttf
+var fontTTF []byte
+
+// 描画パラメータ
+const (
+	defaultFontSize      = 24      // 既定の文字サイズ(px)
+	minFontSize          = 8       // 指定できる文字サイズの下限
+	maxFontSize          = 96      // 指定できる文字サイズの上限
+	defaultMaxLineWidth  = 350     // テキスト自動改行の既定の最大ピクセル幅
+	minMaxLineWidth      = 100     // 吹き出しのしっぽが収まる最大ピクセル幅の下限
+	defaultMaxTextHeight = 400     // 吹き出し内のテキストの既定の最大の高さ(px)
+	defaultMaxLineBytes  = 1 << 20 // 入力から読み込む1行の既定の最大バイト数
+	maxGopherPx          = 300     // Gopher画像の最大表示サイズ(px)
+
+	bubblePadX      = 44  // 吹き出し左右の余白
+	bubblePadY      = 28  // 吹き出し上下の余白
+	bubbleRadius    = 15  // 吹き出し角丸の半径
+	bubbleGap       = 25  // 吹き出しとGopherの間隔
+	tailWidth       = 20  // しっぽの付け根の幅
+	tailLength      = 20  // しっぽの付け根から先端までの高さ
+	lineSpacing     = 4   // 行間の追加ピクセル
+	ellipsis        = "…" // 省略したテキストの末尾に付ける記号
+	defaultTabWidth = 4   // タブを展開する既定の桁の間隔
+	strokeWidth     = 2   // 枠線の太さ
+	minWindowSize   = 300 // ウィンドウ最小サイズ(Metal描画エラー回避)
+	defaultSnapPx   = 20  // ドラッグ後にモニターの端に吸着させる既定の距離
+
+	gopherMarginSide   = 20 // Gopherとウィンドウの左右の端との間隔
+	gopherMarginBottom = 5  // Gopherとウィンドウの下端との間隔
+
+	defaultRevealCPS    = 30   // タイプライター表示の既定速度（文字/秒）
+	defaultRevealMaxCPS = 300  // 長いメッセージでタイプライター表示を速める上限の既定値（文字/秒）
+	revealShare         = 0.3  // 表示時間のうち、タイプライター表示を終えるまでに使う割合
+	defaultFadeSec      = 0.25 // 吹き出しのフェードにかける既定秒数
+
+	bounceSec    = 0.5 // メッセージ到着時に跳ねる秒数
+	bounceHeight = 12  // 跳ねる高さの最大値(px)
+)
+
+// Run はウィンドウを設定してマスコットを表示し、ウィンドウが閉じられるまでブロックする。
+// 終了時にはウィンドウの位置を保存する。
+func (gm *Game) Run() error {
+	if gm.renderOut != "" {
+		return gm.runRender()
+	}
+	ebiten.SetWindowSize(gm.screenWidth, gm.screenHeight)
+
+	// 表示するモニターが指定されていれば移す。ウィンドウの位置はそのモニターの左上を原点とする
+	if m := selectMonitor(gm.monitorIndex); m != nil {
+		ebiten.SetMonitor(m)
+	}
+
+	// 前回終了時の位置があれば復元し、なければ指定した角（既定は右下）に配置する
+	var saved *windowState
+	st, ok, err := loadWindowState()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "load window state: %v\n", err)
+	}
+	if ok {
+		saved = &st
+		gm.bubbleOffX, gm.bubbleOffY = st.BubbleOffsetX, st.BubbleOffsetY
+	}
+	gm.windowX, gm.windowY = initialWindowPosition(gm.corner, monitorBounds(), gm.screenWidth, gm.screenHeight, saved)
+	ebiten.SetWindowPosition(gm.windowX, gm.windowY)
+	ebiten.SetWindowDecorated(false)
+	ebiten.SetWindowFloating(gm.floating)
+	// 画面は変化があったフレームだけ描画し直す
+	ebiten.SetScreenClearedEveryFrame(false)
+	gm.SetClickThrough(gm.clickThrough)
+
+	err = ebiten.RunGameWithOptions(gm, &ebiten.RunGameOptions{
+		ScreenTransparent: gm.background == nil,
+	})
+	close(gm.loopDone)
+	gm.saveState()
+	return err
+}
+
+// layout は事前に計算された描画レイアウト情報。
+type layout struct {
+	gopherX, gopherY float64
+	gopherScale      float64
+	bubbleX, bubbleY float32
+	bubbleW, bubbleH float32
+	bubbleRadius     float32     // 吹き出し角丸の半径
+	tailX            float32     // しっぽ基部のX中心
+	tailDir          tailDir     // しっぽが吹き出しのどちら側から出るか
+	bubbleBelow      bool        // 吹き出しをGopherの下に配置しているか
+	bubbleStyle      bubbleStyle // 吹き出しのスタイル
+	lines            []string
+	lineHeight       float64
+	textH            float64 // テキスト全体の高さ
+	textViewH        float64 // 吹き出しに表示するテキストの高さ。textH より小さければスクロールする
+	headX, headY     float32 // しっぽを向けるGopherの頭の位置
+	bubbleOffX       float32 // 吹き出しを既定の位置からずらした量
+	bubbleOffY       float32
+	tailAimed        bool // しっぽの先端を tailTipX, tailTipY に向けるか
+	tailTipX         float32
+	tailTipY         float32
+	history          []historyBox // 履歴の吹き出し（新しい順）
+	progressH        float32      // 吹き出しの下部に進捗バーのために取った高さ。0 なら進捗バーなし
+}
+
+// tailDir は吹き出しに対してしっぽが出る側を表す。
+type tailDir int
+
+const (
+	tailRight  tailDir = iota // Gopherが吹き出しの右寄りにいる（しっぽは左向きに曲がる）
+	tailCenter                // Gopherが吹き出しの中央付近にいる
+	tailLeft                  // Gopherが吹き出しの左寄りにいる（しっぽは右向きに曲がる）
+)
+
+// charCount は吹き出し内に描画する文字数（改行を除く）を返す。
+func (ly layout) charCount() int {
+	var n int
+	for _, line := range ly.lines {
+		n += utf8.RuneCountInString(line)
+	}
+	return n
+}
+
+// bubbleStyle は吹き出しの見た目の種類。
+type bubbleStyle int
+
+const (
+	styleSpeech bubbleStyle = iota // 話し言葉（尖ったしっぽ）
+	styleThink                     // 考え事（小さな円が連なるしっぽ）
+)
+
+// parseBubbleStyle はメッセージで指定されたスタイル名を bubbleStyle に変換する。未知の名前は話し言葉として扱う。
+func parseBubbleStyle(name string) bubbleStyle {
+	if name == "think" {
+		return styleThink
+	}
+	return styleSpeech
+}
+
+// textAlign は吹き出し内のテキストの横方向の揃え方。
+type textAlign int
+
+const (
+	alignLeft textAlign = iota
+	alignCenter
+	alignRight
+)
+
+// parseTextAlign は揃え方の名前（"left"・"center"・"right"）を textAlign に変換する。未知の名前は ok が false になる。
+func parseTextAlign(name string) (textAlign, bool) {
+	switch name {
+	case "left":
+		return alignLeft, true
+	case "center":
+		return alignCenter, true
+	case "right":
+		return alignRight, true
+	}
+	return alignLeft, false
+}
+
+// --- テキストユーティリティ ---
+
+// wrapText は文字列を指定のピクセル幅で自動改行する。既存の改行(\n)は保持する。
+// 英数字の連続は単語として扱い、1行に収まる限り単語の途中では改行しない。
+// cjk が true なら英数字も含めて文字単位で改行し、行頭禁則を守る。行頭に置けない句読点などは
+// 行末にはみ出させて前の行に残す。
+func wrapText(msg string, face text.Face, maxWidth float64, cjk bool) string {
+	var result []string
+	for _, para := range strings.Split(msg, "\n") {
+		if para == "" {
+			result = append(result, "")
+			continue
+		}
+		// 行の幅は単語を加えるたびに積み上げ、行全体を計測し直さない
+		var line []rune
+		var lineW float64
+		for _, word := range wrapUnits(para, cjk) {
+			wordW := measureText(face, string(word))
+			hang := cjk && len(word) == 1 && noLineStart(word[0])
+			if len(line) > 0 && !hang && appendedWidth(face, line, lineW, word, wordW) > maxWidth {
+				result = append(result, string(line))
+				line, lineW = nil, 0
+				// 折り返し直後の空白は行頭に残さない
+				if len(word) == 1 && unicode.IsSpace(word[0]) {
+					continue
+				}
+			}
+			if len(word) == 1 || wordW <= maxWidth {
+				lineW = appendedWidth(face, line, lineW, word, wordW)
+				line = append(line, word...)
+				continue
+			}
+			// 1行に収まらない長い単語は文字単位で改行する。結合文字や絵文字の途中では改行しない
+			for _, c := range splitClusters(word) {
+				cw := measureText(face, string(c))
+				w := appendedWidth(face, line, lineW, c, cw)
+				if len(line) > 0 && w > maxWidth {
+					result = append(result, string(line))
+					line, w = nil, cw
+				}
+				line = append(line, c...)
+				lineW = w
+			}
+		}
+		if len(line) > 0 {
+			result = append(result, string(line))
+		}
+	}
+	return strings.Join(result, "\n")
+}
+
+// wrapUnits は wrapText で1行に詰める単位に para を分ける。通常は splitWords の単語ごとに分ける。
+// cjk が true なら英数字の連続もまとめず、結合文字や絵文字のまとまりごとに分ける。
+// 長い英数字の単語も行末まで詰めて改行するため、その後の行頭禁則は文字ごとに判定できる。
+func wrapUnits(para string, cjk bool) [][]rune {
+	if cjk {
+		return splitClusters([]rune(para))
+	}
+	return splitWords(para)
+}
+
+// lineBreaks は text/v2 が改行として扱う文字を "\n" に揃える。
+// 折り返しは "\n" だけを改行とみなして幅を積み上げるため、表示前にそろえておく。
+var lineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\v", "\n", "\f", "\n", "\u0085", "\n", "\u2028", "\n", "\u2029", "\n")
+
+// expandTabs はタブを、次の width 桁ごとの位置までの空白に置き換える。桁は行頭からの文字数で数える。
+func expandTabs(s string, width int) string {
+	if !strings.ContainsRune(s, '\t') {
+		return s
+	}
+	var b strings.Builder
+	col := 0
+	for _, r := range s {
+		switch r {
+		case '\t':
+			n := width - col%width
+			b.WriteString(strings.Repeat(" ", n))
+			col += n
+			continue
+		case '\n':
+			col = -1
+		}
+		b.WriteRune(r)
+		col++
+	}
+	return b.String()
+}
+
+// limitLines は折り返したテキストを maxLines 行までに切り詰める。切り詰めた場合は最後の行の末尾に
+// 省略記号を付け、maxWidth に収まらなければ文字を削る。maxLines が 0 以下なら切り詰めない。
+func limitLines(wrapped string, face text.Face, maxLines int, maxWidth float64) string {
+	lines := strings.Split(wrapped, "\n")
+	if maxLines <= 0 || len(lines) <= maxLines {
+		return wrapped
+	}
+	lines = lines[:maxLines]
+	lines[maxLines-1] = truncateText(face, lines[maxLines-1]+ellipsis, maxWidth)
+	return strings.Join(lines, "\n")
+}
+
+// truncateText は s が maxWidth に収まらない場合、収まるまで末尾を削って省略記号を付ける。
+func truncateText(face text.Face, s string, maxWidth float64) string {
+	if measureText(face, s) <= maxWidth {
+		return s
+	}
+	runes := []rune(strings.TrimSuffix(s, ellipsis))
+	for len(runes) > 0 && measureText(face, string(runes)+ellipsis) > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + ellipsis
+}
+
+// splitWords は段落を改行可能な単位に分割する。
+// ASCII英数字の連続は1つの単語にまとめ、それ以外(日本語・記号・空白)は1文字ずつに分ける。
+// 結合文字や複数のコードポイントからなる絵文字は、直前の文字と分けずにまとめる。
+func splitWords(para string) [][]rune {
+	var words [][]rune
+	var word []rune
+	for _, r := range para {
+		if isWordRune(r) {
+			word = append(word, r)
+			continue
+		}
+		if len(word) > 0 {
+			words = append(words, word)
+			word = nil
+		}
+		if n := len(words); n > 0 && joinsCluster(words[n-1], r) {
+			words[n-1] = append(words[n-1], r)
+			continue
+		}
+		words = append(words, []rune{r})
+	}
+	if len(word) > 0 {
+		words = append(words, word)
+	}
+	return words
+}
+
+// isWordRune は単語を構成するASCII英数字かどうかを返す。
+func isWordRune(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// measureText はフォントでレンダリングした際のテキスト幅(px)を返す。
+// drawText と同じ text.Face の送り幅で計測するため、折り返し幅と描画幅が一致する。
+func measureText(face text.Face, str string) float64 {
+	w, _ := text.Measure(str, face, 0)
+	return w
+}
+
+// appendedWidth は描画幅 lineW の line の後ろに、描画幅 wordW の word を続けた際の描画幅(px)を返す。
+// テキストの送り幅は文字ごとの送り幅と隣り合う文字のカーニングの和なので、境目の2文字のカーニングだけを
+// 足せば、続けた文字列全体を measureText で計測し直した値と一致する。
+func appendedWidth(face text.Face, line []rune, lineW float64, word []rune, wordW float64) float64 {
+	if len(line) == 0 {
+		return wordW
+	}
+	if len(word) == 0 {
+		return lineW
+	}
+	a, b := line[len(line)-1], word[0]
+	kern := measureText(face, string([]rune{a, b})) - measureText(face, string(a)) - measureText(face, string(b))
+	return lineW + kern + wordW
+}
+
+// textWidth は表示中のメッセージのスタイルで描画した際の、最も幅の広い行のピクセル幅を返す。
+func (gm *Game) textWidth(lines []string) float64 {
+	var w float64
+	for i, line := range lines {
+		var styles []textStyle
+		if i < len(gm.textStyles) {
+			styles = gm.textStyles[i]
+		}
+		w = math.Max(w, gm.measureRuns(styledRuns([]rune(line), styles)))
+	}
+	return w
+}
+
+// maxTextWidth は複数行のうち最も幅の広い行のピクセル幅を返す。
+func maxTextWidth(face text.Face, lines []string) float64 {
+	var max float64
+	for _, line := range lines {
+		if w := measureText(face, line); w > max {
+			max = w
+		}
+	}
+	return max
+}
+
+// --- リソース読み込み ---
+
+// gopherFrame はGopherアニメーションの1フレーム。静止画は1フレームだけを持つ。
+type gopherFrame struct {
+	image *ebiten.Image
+	delay float64 // 次のフレームに切り替えるまでの秒数
+}
+
+// loadGopherImage は path の画像（PNG・JPEG・GIF）をGopher画像として読み込む。
+// path が空、または読み込めない場合は埋め込みのGopher画像を使う。2つ目の戻り値は path の画像を使ったかどうか。
+func loadGopherImage(path string) ([]gopherFrame, bool, error) {
+	if path != "" {
+		frames, err := loadGopherImageFile(path)
+		if err == nil {
+			return frames, true, nil
+		}
+		fmt.Fprintf(os.Stderr, "%v; using the default image\n", err)
+	}
+	frames, err := decodeGopherFrames(gopherPNG)
+	return frames, false, err
+}
+
+// loadGopherImageFile は path の画像をフレーム列として読み込む。
+func loadGopherImageFile(path string) ([]gopherFrame, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read image %s: %w", path, err)
+	}
+	frames, err := decodeGopherFrames(data)
+	if err != nil {
+		return nil, fmt.Errorf("load image %s: %w", path, err)
+	}
+	return frames, nil
+}
+
+// decodeGopherFrames は画像データをフレーム列に変換する。
+// アニメーションGIFは全フレームを、それ以外の形式は1フレームの静止画として返す。
+func decodeGopherFrames(data []byte) ([]gopherFrame, error) {
+	if bytes.HasPrefix(data, []byte("GIF8")) {
+		return decodeGIFFrames(data)
+	}
+	img, _, err := ebitenutil.NewImageFromReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("new image: %w", err)
+	}
+	return []gopherFrame{{image: img}}, nil
+}
+
+// decodeGIFFrames はアニメーションGIFの各フレームを、差分と破棄方法を反映した全体画像として展開する。
+func decodeGIFFrames(data []byte) ([]gopherFrame, error) {
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode gif: %w", err)
+	}
+	if len(g.Image) == 0 {
+		return nil, fmt.Errorf("decode gif: no frames")
+	}
+
+	bounds := image.Rect(0, 0, g.Config.Width, g.Config.Height)
+	if bounds.Empty() {
+		bounds = g.Image[0].Bounds()
+	}
+	canvas := image.NewRGBA(bounds)
+
+	frames := make([]gopherFrame, 0, len(g.Image))
+	for i, pm := range g.Image {
+		var disposal byte
+		if i < len(g.Disposal) {
+			disposal = g.Disposal[i]
+		}
+		var prev []byte
+		if disposal == gif.DisposalPrevious {
+			prev = bytes.Clone(canvas.Pix)
+		}
+
+		draw.Draw(canvas, pm.Bounds(), pm, pm.Bounds().Min, draw.Over)
+
+		// 遅延0や1はブラウザと同様に0.1秒として扱う
+		delay := 10
+		if i < len(g.Delay) && g.Delay[i] > 1 {
+			delay = g.Delay[i]
+		}
+		frames = append(frames, gopherFrame{
+			image: ebiten.NewImageFromImage(canvas),
+			delay: float64(delay) / 100,
+		})
+
+		switch disposal {
+		case gif.DisposalBackground:
+			draw.Draw(canvas, pm.Bounds(), image.Transparent, image.Point{}, draw.Src)
+		case gif.DisposalPrevious:
+			copy(canvas.Pix, prev)
+		}
+	}
+	return frames, nil
+}
+
+// loadFonts は path のフォント（TrueType・OpenType）と、続けて fallbacks のフォントを読み込む。
+// path が空、または読み込めない場合は埋め込みのフォントを使う。読み込めないフォールバックは飛ばす。
+func loadFonts(path string, fallbacks []string) ([]*opentype.Font, error) {
+	tt, err := opentype.Parse(fontTTF)
+	if err != nil {
+		return nil, fmt.Errorf("parse font: %w", err)
+	}
+	if path != "" {
+		custom, err := loadFontFile(path)
+		if err == nil {
+			tt = custom
+		} else {
+			fmt.Fprintf(os.Stderr, "%v; using the default font\n", err)
+		}
+	}
+
+	fonts := []*opentype.Font{tt}
+	for _, fb := range fallbacks {
+		tt, err := loadFontFile(fb)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v; skipping the fallback font\n", err)
+			continue
+		}
+		fonts = append(fonts, tt)
+	}
+	return fonts, nil
+}
+
+// newFontFaces は fonts から size の大きさのフェイスを作る。
+// フォントが複数ある場合は、文字ごとにグリフを持つ最初のフォントで描画するフォールバック付きのフェイスを返す。
+// scale は描画先のデバイススケールで、文字を scale 倍の解像度で描画する。
+func newFontFaces(fonts []*opentype.Font, size int, scale float64) (text.Face, error) {
+	faces := make([]text.Face, 0, len(fonts))
+	for _, tt := range fonts {
+		face, err := newFontFace(tt, size, scale)
+		if err != nil {
+			return nil, err
+		}
+		faces = append(faces, face)
+	}
+	if len(faces) == 1 {
+		return faces[0], nil
+	}
+	mf, err := text.NewMultiFace(faces...)
+	if err != nil {
+		return nil, fmt.Errorf("new multi face: %w", err)
+	}
+	return mf, nil
+}
+
+// newFontFace はフォントから size の大きさのフェイスを作る。DPI を scale 倍にし、scale 倍の解像度で描画する。
+func newFontFace(tt *opentype.Font, size int, scale float64) (text.Face, error) {
+	face, err := opentype.NewFace(tt, &opentype.FaceOptions{
+		Size:    float64(size),
+		DPI:     72 * scale,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("new font face: %w", err)
+	}
+	return text.NewGoXFace(face), nil
+}
+
+// loadFontFile は path のフォントファイルを読み込む。
+func loadFontFile(path string) (*opentype.Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read font %s: %w", path, err)
+	}
+	tt, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse font %s: %w", path, err)
+	}
+	return tt, nil
+}
+
+// --- 設定 ---
+
+// DisplayDuration はメッセージの表示時間の設定。
+// 表示秒数は Base + PerChar*文字数 で計算し、Max を上限とする。
+type DisplayDuration struct {
+	Base    float64 // 基本の表示秒数。0 の場合は次のメッセージで置き換わるまで表示し続ける
+	PerChar float64 // 1文字あたりの追加秒数
+	Max     float64 // 表示秒数の上限（0で上限なし）
+}
+
+// frames はメッセージの表示フレーム数を返す。0 は時間切れで消えないことを表す。
+func (d DisplayDuration) frames(message string) int {
+	if d.Base <= 0 {
+		return 0
+	}
+	sec := d.Base + d.PerChar*float64(utf8.RuneCountInString(message))
+	if d.Max > 0 {
+		sec = math.Min(sec, d.Max)
+	}
+	return int(sec * float64(ebiten.TPS()))
+}
+
+// --- レイアウト計算 ---
+
+// LayoutConfig は吹き出しとGopherの配置に使う寸法(px)。DefaultLayoutConfig の値をもとに必要な項目だけ変更して使う。
+type LayoutConfig struct {
+	FontSize           int     // 文字サイズ
+	LineSpacing        float64 // 行間の追加ピクセル
+	MaxTextHeight      float64 // 吹き出し内のテキストの最大の高さ。0で上限なし
+	MaxGopherPx        float64 // Gopher画像の最大表示サイズ
+	GopherBoxW         float64 // Gopher画像を収める枠の幅。0 なら MaxGopherPx 四方に収める
+	GopherBoxH         float64 // Gopher画像を収める枠の高さ。0 なら MaxGopherPx 四方に収める
+	Letterbox          bool    // 画像の縦横比に関わらず枠の大きさ分の場所を取り、画像を枠の下端・左右中央に置く
+	BubblePadX         float64 // 吹き出し左右の余白（左右の合計）
+	BubblePadY         float64 // 吹き出し上下の余白（上下の合計）
+	BubbleRadius       float64 // 吹き出し角丸の半径
+	StrokeWidth        float64 // 吹き出しの枠線の太さ
+	BubbleGap          float64 // 吹き出しとGopherの間隔
+	GopherMarginSide   float64 // Gopherとウィンドウの左右の端との間隔
+	GopherMarginBottom float64 // Gopherとウィンドウの下端との間隔
+	TailWidth          float64 // しっぽの付け根の幅
+	TailLength         float64 // しっぽの付け根から先端までの高さ。0でしっぽを描かない
+	MinWindowSize      int     // ウィンドウ最小サイズ(Metal描画エラー回避)
+	FixedWidth         int     // 固定するウィンドウの幅。FixedHeight と合わせて正の値なら、内容に合わせてリサイズしない
+	FixedHeight        int     // 固定するウィンドウの高さ
+}
+
+// DefaultLayoutConfig は既定の寸法を返す。
+func DefaultLayoutConfig() LayoutConfig {
+	return LayoutConfig{
+		FontSize:           defaultFontSize,
+		LineSpacing:        lineSpacing,
+		MaxTextHeight:      defaultMaxTextHeight,
+		MaxGopherPx:        maxGopherPx,
+		BubblePadX:         bubblePadX,
+		BubblePadY:         bubblePadY,
+		BubbleRadius:       bubbleRadius,
+		StrokeWidth:        strokeWidth,
+		BubbleGap:          bubbleGap,
+		GopherMarginSide:   gopherMarginSide,
+		GopherMarginBottom: gopherMarginBottom,
+		TailWidth:          tailWidth,
+		TailLength:         tailLength,
+		MinWindowSize:      minWindowSize,
+	}
+}
+
+// calcGopherScale は画像サイズ size を縦横比を保って boxW×boxH の枠に収めるスケール係数を返す。
+func calcGopherScale(size image.Point, boxW, boxH float64) float64 {
+	w, h := float64(size.X), float64(size.Y)
+	return math.Min(boxW/w, boxH/h)
+}
+
+// fixedSize はウィンドウの大きさを固定する設定かどうかを返す。
+func (cfg LayoutConfig) fixedSize() bool {
+	return cfg.FixedWidth > 0 && cfg.FixedHeight > 0
+}
+
+// gopherBox はGopher画像を収める枠の幅と高さを返す。
+func (cfg LayoutConfig) gopherBox() (float64, float64) {
+	if cfg.GopherBoxW > 0 && cfg.GopherBoxH > 0 {
+		return cfg.GopherBoxW, cfg.GopherBoxH
+	}
+	return cfg.MaxGopherPx, cfg.MaxGopherPx
+}
+
+// calcLayout は全要素のサイズ・配置を一括計算し、ウィンドウサイズも返す。
+// Gopherはウィンドウ下部の、c が左側の角なら左端、右側の角なら右端に固定する。
+// below が true の場合は上下を入れ替え、Gopherをウィンドウ上部に、吹き出しをその下に配置する。
+// gopherSize はGopher画像の元のサイズ、textW はメッセージの最も幅の広い行の描画幅(px)。
+// progress が true ならテキストの下に進捗バーの場所を取る。
+// historyW は履歴の吹き出しのテキストの描画幅(px)で、新しい順に現在の吹き出しから離れる向きに積む。
+// ウィンドウの大きさを固定する設定では、ウィンドウは常にその大きさにし、テキストは収まらない分をスクロールさせる。
+// 寸法はすべて cfg から読み、パッケージの状態には依存しない。
+func calcLayout(gopherSize image.Point, textW float64, message string, progress bool, historyW []float64, c corner, below bool, cfg LayoutConfig) (layout, int, int) {
+	// Gopherサイズ（固定基準）。レターボックスの場合は画像ではなく枠の大きさで配置する
+	boxW, boxH := cfg.gopherBox()
+	scale := calcGopherScale(gopherSize, boxW, boxH)
+	imageW := float64(gopherSize.X) * scale
+	imageH := float64(gopherSize.Y) * scale
+	gopherW, gopherH := imageW, imageH
+	if cfg.Letterbox {
+		gopherW, gopherH = boxW, boxH
+	}
+
+	// Gopherの固定位置（ウィンドウ下部の左右どちらかの端に固定マージン）
+	gopherMarginSide := cfg.GopherMarginSide
+	gopherMarginBottom := cfg.GopherMarginBottom
+	bubbleGap := cfg.BubbleGap
+
+	// テキスト計測
+	lines := strings.Split(message, "\n")
+	lineH := float64(cfg.FontSize) + cfg.LineSpacing
+	maxTextH := cfg.MaxTextHeight
+
+	// 履歴の吹き出しは1行分の高さで、余白は現在の吹き出しの半分にする
+	historyH := lineH + cfg.BubblePadY/2
+	var historyTotalH, historyMaxW float64
+	for _, w := range historyW {
+		historyTotalH += historyH + historyGap
+		historyMaxW = math.Max(historyMaxW, w+cfg.BubblePadX/2)
+	}
+
+	var progressH float64
+	if progress && message != "" {
+		progressH = progressBarH + progressGap
+	}
+
+	// ウィンドウの大きさが固定なら、Gopherと履歴を除いて残る高さにテキストを収める
+	if cfg.fixedSize() {
+		fitH := float64(cfg.FixedHeight) - gopherH - gopherMarginBottom - bubbleGap - historyTotalH - 20 - cfg.BubblePadY - progressH
+		if maxTextH <= 0 || fitH < maxTextH {
+			maxTextH = fitH
+		}
+	}
+
+	// テキストが maxTextH より高い場合は吹き出しの高さを抑え、テキストをスクロールさせる
+	var bw, bh, textH, viewH float64
+	if message != "" {
+		textH = float64(len(lines)) * lineH
+		viewH = textH
+		if maxTextH > 0 || cfg.fixedSize() {
+			viewH = math.Min(textH, math.Max(maxTextH, lineH))
+		}
+		bw = textW + cfg.BubblePadX
+		bh = viewH + cfg.BubblePadY + progressH
+		if progressH > 0 {
+			bw = math.Max(bw, progressMinW+cfg.BubblePadX)
+		}
+	}
+
+	// ウィンドウサイズ（Gopherの位置が変わらないようにGopher基準で計算）
+	// メッセージがなくても吹き出し分のスペースを確保し、初回入力時の急激なリサイズを防ぐ
+	minBubbleH := lineH + cfg.BubblePadY // 1行分の最小バブル高さ
+	effectiveBH := math.Max(bh, minBubbleH)
+	sw := int(math.Max(math.Max(bw, historyMaxW)+80, gopherW+gopherMarginSide+20))
+	sh := int(gopherH + gopherMarginBottom + bubbleGap + effectiveBH + historyTotalH + 20)
+	sw = max(sw, cfg.MinWindowSize)
+	sh = max(sh, cfg.MinWindowSize)
+	if cfg.fixedSize() {
+		sw, sh = cfg.FixedWidth, cfg.FixedHeight
+	}
+
+	// Gopher配置（常にウィンドウ下部の角に固定）
+	gopherX := float64(sw) - gopherW - gopherMarginSide
+	if c.left() {
+		gopherX = gopherMarginSide
+	}
+	gopherY := float64(sh) - gopherH - gopherMarginBottom
+
+	// 吹き出し配置（Gopherの上に配置）
+	bx32 := float32(float64(sw)/2) - float32(bw)/2
+	by32 := float32(gopherY - bh - bubbleGap)
+
+	// 上下反転時はGopherの上に吹き出しと同じ余白を取り、その下に吹き出しを置く
+	if below {
+		gopherY = 20
+		by32 = float32(gopherY + gopherH + bubbleGap)
+	}
+
+	// レターボックスの枠の中で、画像を下端・左右中央に置く
+	gopherX += (gopherW - imageW) / 2
+	gopherY += gopherH - imageH
+
+	// しっぽ配置（Gopherの頭の真上に基部を置き、頭のある側へ向ける）
+	headX := float32(gopherX + imageW/2)
+	headY := float32(gopherY)
+	if below {
+		headY = float32(gopherY + imageH)
+	}
+	// 角丸は吹き出しの短い辺の半分までにする
+	radius := float32(math.Min(cfg.BubbleRadius, math.Min(bw, bh)/2))
+	tailX, dir := calcTail(headX, bx32, float32(bw), radius)
+
+	// 履歴の吹き出しを、上向きの場合は現在の吹き出しの上に、上下反転時は下に積む
+	history := make([]historyBox, len(historyW))
+	edge, step := float64(by32), -1.0 // 次の吹き出しを接する位置と、積む向き
+	if below {
+		edge, step = float64(by32)+bh, 1
+	}
+	if bh > 0 {
+		edge += step * historyGap
+	}
+	for i, w := range historyW {
+		hw := w + cfg.BubblePadX/2
+		y := edge
+		if step < 0 {
+			y -= historyH
+		}
+		history[i] = historyBox{x: float32(float64(sw)/2 - hw/2), y: float32(y), w: float32(hw), h: float32(historyH)}
+		edge += step * (historyH + historyGap)
+	}
+
+	ly := layout{
+		gopherX:      gopherX,
+		gopherY:      gopherY,
+		gopherScale:  scale,
+		bubbleX:      bx32,
+		bubbleY:      by32,
+		bubbleW:      float32(bw),
+		bubbleH:      float32(bh),
+		bubbleRadius: radius,
+		tailX:        tailX,
+		tailDir:      dir,
+		bubbleBelow:  below,
+		lines:        lines,
+		lineHeight:   lineH,
+		textH:        textH,
+		textViewH:    viewH,
+		headX:        headX,
+		headY:        headY,
+		history:      history,
+		progressH:    float32(progressH),
+	}
+	return ly, sw, sh
+}
+
+// calcTail はGopherの頭のX座標から、しっぽ基部のX座標と向きを求める。
+// 基部は半径 r の角丸部分にかからない範囲に収める。
+func calcTail(headX, bx, bw, r float32) (float32, tailDir) {
+	dir := tailCenter
+	switch center := bx + bw/2; {
+	case headX > center+bw*0.1:
+		dir = tailRight
+	case headX < center-bw*0.1:
+		dir = tailLeft
+	}
+
+	lo := bx + r + 10
+	hi := bx + bw - r - 10
+	if hi < lo {
+		return bx + bw/2, dir
+	}
+	return min(max(headX, lo), hi), dir
+}
+
+// --- Game 生成 ---
+
+var _ ebiten.Game = (*Game)(nil)
+
+// Game はデスクトップマスコットの状態を保持する。ebiten.Game を実装する。
+type Game struct {
+	gopherImage  *ebiten.Image            // レイアウト計算とドラッグ判定のサイズ基準となる画像
+	gopherFrames []gopherFrame            // アニメーションのフレーム列（静止画は1フレーム）
+	frameIndex   int                      // 表示中のフレーム番号
+	frameElapsed float64                  // 表示中のフレームの経過秒数
+	sprites      *spriteSheet             // 表情のスプライトシート（未指定なら nil）
+	namedImages  map[string][]gopherFrame // メッセージで名前を指定して切り替えるGopher画像
+	messageImage []gopherFrame            // 表示中のメッセージで指定された画像。nil なら通常の画像を描画する
+	expression   string                   // 表示中の表情名
+	keywords     []keywordReaction        // メッセージのキーワードに応じて切り替える表情
+
+	// まばたき用状態
+	eyes       []gopherEye // まぶたを描く目の位置（未知の画像では nil）
+	blinkTimer int         // 次のまばたきまでの残りフレーム数
+	blinkFrame int         // まばたき中の残りフレーム数（0なら目を開いている）
+
+	bounceTimer    int // 跳ねるアニメーションの残りフレーム数
+	fontFace       text.Face
+	layoutCfg      LayoutConfig     // レイアウト計算に使う寸法
+	defaultAlign   textAlign        // メッセージで指定がない場合のテキストの揃え方
+	textAlign      textAlign        // 表示中のメッセージのテキストの揃え方
+	textStyles     [][]textStyle    // 表示中のメッセージの各行・各文字の強調
+	links          []string         // 表示中のメッセージに含まれる URL（出現順）
+	messageText    string           // 表示中のメッセージの記法を解釈したテキスト
+	history        []historyEntry   // 過去のメッセージの履歴（新しい順）
+	historyLen     int              // 履歴として表示する件数。0で表示しない
+	historyFalloff float64          // 履歴が1件古くなるごとに掛ける不透明度
+	historyLayer   *ebiten.Image    // 履歴の吹き出しを不透明度付きで合成するためのオフスクリーン画像
+	antiAlias      bool             // 吹き出しなどの図形にアンチエイリアスをかけるか
+	strokeStyle    strokeStyle      // 吹き出しの枠線の端と角の形
+	hasProgress    bool             // 表示中のメッセージに進捗バーがあるか
+	progress       float64          // 進捗バーに表示している進捗（0〜1）。progressTarget に向かって動く
+	progressTarget float64          // 表示中のメッセージの進捗（0〜1）
+	recent         []message        // 最近表示したメッセージ（新しい順）。ホイールで遡って表示し直す
+	browseIndex    int              // 遡って表示している recent の位置。-1 ならライブの表示
+	liveShown      bool             // 遡り始めたときにメッセージを表示していたか
+	liveTimer      int              // 遡り始めたときのメッセージの残り表示フレーム数
+	wheelAcc       float64          // 遡るためのホイールの回転量のうち、1件分に満たない端数
+	textColor      color.Color      // 表示中のメッセージの文字色
+	outlineWidth   float64          // 文字の縁取りの太さ(px)。0 で縁取りしない
+	outlineColor   color.Color      // 文字の縁取りの色
+	codeFace       text.Face        // コードブロック用の等幅フォント
+	fonts          []*opentype.Font // 主フォントとフォールバックのフォント
+	boldFace       text.Face        // 太字のフォント。nil なら太字は通常の書体から合成する
+	boldFonts      []*opentype.Font // 太字のフォントと、その後に続けるフォールバック。nil なら太字は合成する
+	drawBoldFace   text.Face        // 太字の描画用のフォント
+	cjkFonts       []*opentype.Font // CJK のメッセージに使うフォントと、その後に続けるフォールバック。nil なら使い分けない
+	cjkFace        text.Face        // CJK のメッセージの計測用のフォント
+	drawCJKFace    text.Face        // CJK のメッセージの描画用のフォント
+	drawFace       text.Face        // 描画用のフォント。fontFace をデバイススケール倍の解像度にしたもの
+	drawCodeFace   text.Face        // 描画用の等幅フォント
+	deviceScale    float64          // 描画先の画面のデバイススケール
+	maxLineWidth   float64          // テキスト自動改行の最大ピクセル幅
+	maxLines       int              // 折り返し後に表示する最大の行数。0 で上限なし
+	tabWidth       int              // タブを展開する桁の間隔
+	wrapCache      *wrapCache       // 最近のメッセージの折り返し結果
+	renderOut      string           // 空でなければ、ウィンドウを表示せず renderMessage を描画した PNG をこのパスに書き出して終了する
+	renderMessage  string           // PNG に書き出すメッセージ
+	logger         *slog.Logger     // 表示したメッセージを記録する。nil なら記録しない
+	maxLineBytes   int              // 入力から読み込む1行の最大バイト数
+	clearSentinel  string           // 入力された行がこれと一致したら表示中のメッセージを消す。空なら消さない
+	stripANSI      bool             // メッセージから ANSI エスケープシーケンスを取り除くか
+	idlePhrases    []string         // 待機中に話すひとことの一覧
+	idleSec        float64          // メッセージがない状態がこの秒数続いたらひとことを話す。0で話さない
+	idleTimer      int              // メッセージがない状態が続いているフレーム数
+	scrollY        float64          // 吹き出し内のテキストのスクロール量(px)
+	scrollManual   bool             // ホイールでスクロールしたか。した場合は自動スクロールしない
+	resizeSec      float64          // ウィンドウのリサイズにかける秒数。0で即座にリサイズする
+	resize         *resizeTween     // 実行中のリサイズのアニメーション
+	resizeLayer    *ebiten.Image    // リサイズ中に最終的なサイズで描画するためのオフスクリーン画像
+	sound          *audio.Player    // メッセージの通知音
+	muted          bool             // 通知音をミュートしているか
+	speaker        *speaker         // メッセージの読み上げ。nil なら読み上げない
+	screenWidth    int
+	screenHeight   int
+	layout         layout
+	corner         corner          // ウィンドウを配置した画面の角
+	placement      placement       // 吹き出しをGopherの上下どちらに置くか
+	monitorIndex   int             // 表示するモニターの番号。負なら起動時のモニター
+	hasMessage     bool            // メッセージが存在するか
+	msgTimer       int             // メッセージ表示残りフレーム数（0で消える）
+	sticky         bool            // すべてのメッセージを時間切れで消さない
+	duration       DisplayDuration // メッセージの表示時間設定
+	bubbleFill     color.Color     // 吹き出しの塗り色
+	bubbleStroke   color.Color     // 吹き出しの枠線色
+	shadowOffset   float64         // 吹き出しの影をずらす量(px)。0で影を描かない
+	shadowOpacity  float64         // 吹き出しの影の不透明度（0〜1）
+
+	// 受信したメッセージの待ち行列と、Update で実行する処理（入力用のgoroutineと共有するため mu で保護する）
+	mu        sync.Mutex
+	msgQueue  []message
+	tasks     []func()
+	quit      bool          // 次の Update で終了する
+	inputDone bool          // 入力が終わったら終了する設定で、入力が終わったか
+	loopDone  chan struct{} // メインループが終了すると閉じる
+
+	// 入力元のgoroutineとメッセージの受け口の後始末（Close で行う）
+	ctx       context.Context // Close で取り消され、入力元のgoroutineを終わらせる
+	cancel    context.CancelFunc
+	closers   []func() error // HTTP サーバーやソケットを閉じる処理
+	closeOnce sync.Once
+	closeErr  error
+
+	// タイプライター表示用状態
+	revealCPS     float64 // 1秒あたりに表示する最小の文字数（0で一括表示）
+	revealMaxCPS  float64 // 1秒あたりに表示する最大の文字数
+	messageCPS    float64 // 表示中のメッセージのタイプライター表示の速度（文字/秒）
+	revealedChars int     // 表示済みの文字数
+	revealAcc     float64 // 1文字に満たない表示進捗の端数
+
+	// フェード用状態
+	fadeSec      float64       // フェードイン・アウトにかける秒数（0でフェードなし）
+	bubbleAlpha  float64       // 吹き出しの不透明度（0〜1）
+	bubbleLayer  *ebiten.Image // 吹き出しを不透明度付きで合成するためのオフスクリーン画像
+	opacityLayer *ebiten.Image // ウィンドウ全体を不透明度付きで合成するためのオフスクリーン画像
+	opacity      float64       // ウィンドウ全体の不透明度（0〜1）
+	blurOpacity  float64       // フォーカスがない間にウィンドウ全体の不透明度に掛ける値（0〜1）。1で薄くしない
+	blurFadeSec  float64       // フォーカスの変化で薄くする・戻す際にかける秒数。0で即座に切り替える
+	focusDim     float64       // 現在ウィンドウ全体の不透明度に掛けている値
+	background   color.Color   // ウィンドウの背景色。nil なら透明
+
+	// ウィンドウ位置（終了時の保存用に Update で更新する）
+	windowX int
+	windowY int
+
+	// クリック透過モード（マウス操作を下のウィンドウに通し、ドラッグも無効にする）
+	clickThrough bool
+
+	// 省電力モード（何も動いていない間は TPS を下げる）
+	lowPower bool
+
+	// 再描画の判定用状態
+	dirty     bool        // 次の Draw で描画し直す
+	drawn     drawState   // 最後に dirty を判定した時点の描画に関わる状態
+	drawnSize image.Point // 最後に描画した画面のサイズ
+
+	// 右クリックメニュー用状態
+	menuOpen       bool
+	menuX, menuY   int     // メニューを開いた位置
+	suppressDrag   bool    // メニュー操作のクリックで、ボタンを離すまでドラッグを始めない
+	bubblePressed  bool    // 吹き出しの上でボタンを押しているか
+	bubbleDragging bool    // 吹き出しをドラッグしているか
+	bubbleStartX   float32 // ドラッグ開始時の吹き出しのずらし量
+	bubbleStartY   float32
+	bubbleOffX     float32 // 吹き出しを既定の位置からずらした量。再起動後も引き継ぐ
+	bubbleOffY     float32
+
+	// ドラッグ用状態
+	dragging     bool
+	touchID      ebiten.TouchID         // Gopherをドラッグしている指
+	touching     bool                   // 指でGopherをドラッグしているか
+	lastClick    time.Time              // 最後にGopherをクリックした時刻
+	doubleClick  time.Duration          // ダブルクリックとみなす2回のクリックの最大間隔
+	floating     bool                   // ウィンドウを常に最前面に表示するか
+	onToggle     func()                 // 最前面表示やミュートを切り替えた後に Update の中で呼ぶ処理（トレイのチェックの更新）
+	noticing     bool                   // 最前面表示の切り替えなどの通知を、待ち行列を待たずに表示しているか
+	noticeLive   bool                   // 通知の前にメッセージ（recent[0]）を表示していたか
+	noticeTimer  int                    // 通知の前に表示していたメッセージの残りの表示フレーム数
+	cursorShape  ebiten.CursorShapeType // 設定中のカーソルの形
+	lookAtCursor bool                   // カーソルが近くにあるとき、Gopherを少しカーソルの方へ寄せて描画するか
+	leanX, leanY float64                // Gopherを描画する位置のカーソルの方へのずれ(px)
+	snapPx       int                    // ドラッグを終えた際にモニターの端に吸着させる距離(px)。0で吸着しない
+	dragStartX   int
+	dragStartY   int
+}
+
+// New は opts の設定でマスコットを作成する。
+// 設定に応じて HTTP サーバーや名前付きパイプなどのメッセージの受け口も開始する。
+func New(opts Options) (*Game, error) {
+	if opts.Theme != "" {
+		if err := opts.applyTheme(opts.Theme); err != nil {
+			return nil, fmt.Errorf("load theme: %w", err)
+		}
+	}
+	frames, custom, err := loadGopherImage(opts.Image)
+	if err != nil {
+		return nil, err
+	}
+	sprites, err := loadSpriteSheet(opts.SpriteSheet, opts.SpriteSize, opts.Expressions)
+	if err != nil {
+		return nil, err
+	}
+	namedImages, err := loadNamedImages(opts.ImageDir)
+	if err != nil {
+		return nil, err
+	}
+	keywords, err := parseKeywords(opts.Keywords)
+	if err != nil {
+		return nil, fmt.Errorf("parse keywords: %w", err)
+	}
+	// スプライトシートがある場合はフレームのサイズをレイアウトの基準にする
+	img := frames[0].image
+	eyes := defaultGopherEyes
+	if custom {
+		// 差し替えた画像の目の位置は分からないため、まばたきは無効にする
+		eyes = nil
+	}
+	if sprites != nil {
+		img = sprites.frame(expressionNeutral)
+		eyes = nil
+	}
+	fontSize := opts.FontSize
+	if fontSize < minFontSize || fontSize > maxFontSize {
+		fontSize = defaultFontSize
+	}
+	tabWidth := opts.TabWidth
+	if tabWidth <= 0 {
+		tabWidth = defaultTabWidth
+	}
+	fonts, err := loadFonts(opts.Font, opts.FallbackFonts)
+	if err != nil {
+		return nil, err
+	}
+	fontFace, err := newFontFaces(fonts, fontSize, 1)
+	if err != nil {
+		return nil, err
+	}
+	var boldFonts []*opentype.Font
+	var boldFace text.Face
+	if opts.BoldFont != "" {
+		if tt, err := loadFontFile(opts.BoldFont); err == nil {
+			boldFonts = append([]*opentype.Font{tt}, fonts[1:]...)
+			if boldFace, err = newFontFaces(boldFonts, fontSize, 1); err != nil {
+				return nil, err
+			}
+		} else {
+			fmt.Fprintf(os.Stderr, "%v; using synthesized bold\n", err)
+		}
+	}
+	var cjkFonts []*opentype.Font
+	var cjkFace text.Face
+	if opts.CJKFont != "" {
+		if tt, err := loadFontFile(opts.CJKFont); err == nil {
+			cjkFonts = append([]*opentype.Font{tt}, fonts...)
+			if cjkFace, err = newFontFaces(cjkFonts, fontSize, 1); err != nil {
+				return nil, err
+			}
+		} else {
+			fmt.Fprintf(os.Stderr, "%v; using the default fonts for CJK\n", err)
+		}
+	}
+
+	maxLineWidth := defaultMaxLineWidth
+	if opts.MaxWidth > 0 {
+		maxLineWidth = max(opts.MaxWidth, minMaxLineWidth)
+	}
+	sound, err := loadSoundPlayer(opts.Sound)
+	if err != nil && opts.Sound != "" {
+		fmt.Fprintf(os.Stderr, "%v; using the default sound\n", err)
+		sound, err = loadSoundPlayer("")
+	}
+	if err != nil {
+		return nil, err
+	}
+	var spk *speaker
+	if opts.TTS {
+		spk, err = newSpeaker()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v; text-to-speech is disabled\n", err)
+		}
+	}
+	codeFace, err := loadCodeFace(fontSize, 1, fontFace)
+	if err != nil {
+		return nil, err
+	}
+	align, _ := parseTextAlign(opts.Align)
+
+	// 初期状態：メッセージなしのレイアウト
+	crn := parseCorner(opts.Corner)
+	layoutCfg := DefaultLayoutConfig()
+	layoutCfg.FontSize = fontSize
+	layoutCfg.LineSpacing = max(opts.LineSpacing, 0)
+	layoutCfg.MaxTextHeight = float64(max(opts.MaxHeight, 0))
+	layoutCfg.BubbleRadius = max(opts.BubbleRadius, 0)
+	layoutCfg.BubblePadX = max(opts.BubblePadX, 0)
+	layoutCfg.BubblePadY = max(opts.BubblePadY, 0)
+	layoutCfg.StrokeWidth = max(opts.StrokeWidth, 0)
+	strokeStyle, err := parseStrokeStyle(opts.StrokeCap, opts.StrokeJoin)
+	if err != nil {
+		return nil, fmt.Errorf("parse stroke style: %w", err)
+	}
+	layoutCfg.BubbleGap = max(opts.BubbleGap, 0)
+	layoutCfg.TailWidth = max(opts.TailWidth, 0)
+	layoutCfg.TailLength = max(opts.TailLength, 0)
+	layoutCfg.GopherMarginSide = max(opts.MarginSide, 0)
+	layoutCfg.GopherMarginBottom = max(opts.MarginBottom, 0)
+	if opts.Size > 0 {
+		layoutCfg.MaxGopherPx = float64(opts.Size)
+	}
+	if opts.Box != "" {
+		w, h, err := parseSize(opts.Box)
+		if err != nil {
+			return nil, fmt.Errorf("parse gopher box: %w", err)
+		}
+		layoutCfg.GopherBoxW, layoutCfg.GopherBoxH = float64(w), float64(h)
+	}
+	layoutCfg.Letterbox = opts.Letterbox
+	if opts.FixedSize != "" {
+		w, h, err := parseSize(opts.FixedSize)
+		if err != nil {
+			return nil, fmt.Errorf("parse fixed size: %w", err)
+		}
+		layoutCfg.FixedWidth, layoutCfg.FixedHeight = w, h
+		// 吹き出しがウィンドウの幅に収まるよう折り返す
+		maxLineWidth = max(min(maxLineWidth, w-80-int(layoutCfg.BubblePadX)), minMaxLineWidth)
+	}
+	fixedBelow := layoutCfg.fixedSize() && parsePlacement(opts.Placement) == placementBelow
+	ly, sw, sh := calcLayout(img.Bounds().Size(), 0, "", false, nil, crn, fixedBelow, layoutCfg)
+
+	gm := &Game{
+		gopherImage:    img,
+		gopherFrames:   frames,
+		sprites:        sprites,
+		namedImages:    namedImages,
+		keywords:       keywords,
+		expression:     expressionNeutral,
+		eyes:           eyes,
+		blinkTimer:     nextBlinkFrames(),
+		fontFace:       fontFace,
+		layoutCfg:      layoutCfg,
+		codeFace:       codeFace,
+		fonts:          fonts,
+		boldFace:       boldFace,
+		boldFonts:      boldFonts,
+		drawBoldFace:   boldFace,
+		drawFace:       fontFace,
+		cjkFonts:       cjkFonts,
+		cjkFace:        cjkFace,
+		drawCJKFace:    cjkFace,
+		drawCodeFace:   codeFace,
+		deviceScale:    1,
+		maxLineWidth:   float64(maxLineWidth),
+		maxLines:       max(opts.MaxLines, 0),
+		sticky:         opts.Sticky,
+		tabWidth:       tabWidth,
+		wrapCache:      newWrapCache(),
+		renderOut:      opts.RenderOut,
+		renderMessage:  opts.Message,
+		logger:         opts.Logger,
+		maxLineBytes:   max(opts.MaxLineBytes, bufio.MaxScanTokenSize),
+		stripANSI:      opts.StripANSI,
+		idleSec:        opts.IdleSec,
+		resizeSec:      opts.ResizeSec,
+		monitorIndex:   opts.Monitor,
+		sound:          sound,
+		muted:          opts.Mute,
+		speaker:        spk,
+		defaultAlign:   align,
+		screenWidth:    sw,
+		screenHeight:   sh,
+		layout:         ly,
+		corner:         crn,
+		placement:      parsePlacement(opts.Placement),
+		duration:       opts.Duration,
+		bubbleFill:     opts.BubbleFill,
+		textColor:      color.Black,
+		bubbleStroke:   opts.BubbleStroke,
+		shadowOffset:   opts.ShadowOffset,
+		shadowOpacity:  opts.ShadowOpacity,
+		opacity:        min(max(opts.Opacity, 0), 1),
+		blurOpacity:    min(max(opts.BlurOpacity, 0), 1),
+		blurFadeSec:    opts.BlurFadeSec,
+		focusDim:       1,
+		revealCPS:      opts.RevealCPS,
+		revealMaxCPS:   max(opts.RevealMaxCPS, opts.RevealCPS),
+		fadeSec:        opts.FadeSec,
+		clickThrough:   opts.ClickThrough,
+		loopDone:       make(chan struct{}),
+		lowPower:       opts.LowPower,
+		background:     opts.Background,
+		doubleClick:    time.Duration(opts.DoubleClickMs) * time.Millisecond,
+		floating:       true,
+		snapPx:         opts.SnapPx,
+		historyLen:     opts.HistoryLen,
+		historyFalloff: min(max(opts.HistoryFalloff, 0), 1),
+		browseIndex:    -1,
+		clearSentinel:  opts.ClearSentinel,
+		outlineWidth:   opts.OutlineWidth,
+		lookAtCursor:   opts.LookAtCursor,
+		outlineColor:   opts.OutlineColor,
+		antiAlias:      opts.AntiAlias,
+		strokeStyle:    strokeStyle,
+	}
+	if gm.bubbleFill == nil {
+		gm.bubbleFill = color.White
+	}
+	if opts.IdleSec > 0 {
+		gm.idlePhrases = loadIdlePhrases(opts.IdleFile)
+	}
+	if gm.bubbleStroke == nil {
+		gm.bubbleStroke = color.Black
+	}
+	if gm.outlineColor == nil {
+		gm.outlineColor = color.White
+	}
+
+	gm.ctx, gm.cancel = context.WithCancel(context.Background())
+	if err := gm.startHTTPServer(opts.HTTPAddr); err != nil {
+		gm.Close()
+		return nil, err
+	}
+
+	if err := gm.startPipeReader(opts.PipePath); err != nil {
+		gm.Close()
+		return nil, err
+	}
+	if err := gm.startCommandServer(opts.SocketPath); err != nil {
+		gm.Close()
+		return nil, err
+	}
+	if opts.Tray {
+		if err := gm.startTray(); err != nil {
+			fmt.Fprintf(os.Stderr, "%v; the tray icon is disabled\n", err)
+		}
+	}
+
+	// 入力元から行を読み取るgoroutine
+	sources := opts.Sources
+	if opts.Input != nil {
+		sources = append(sources, ReaderSource{R: opts.Input, MaxLineBytes: gm.maxLineBytes})
+	}
+	if len(sources) > 0 {
+		// 他にメッセージを受け付ける経路がなければ、すべての入力の終わりで終了できる
+		var done func()
+		if opts.ExitOnEOF && opts.HTTPAddr == "" && opts.PipePath == "" && opts.SocketPath == "" {
+			done = func() { gm.runOnUpdate(func() { gm.inputDone = true }) }
+		}
+		gm.readSources(sources, done)
+	}
+
+	return gm, nil
+}
+
+// Say はテキストをメッセージとして表示する。表示中のメッセージがあれば、その後に順番に表示する。
+// 任意のgoroutineから呼び出せる。
+func (gm *Game) Say(text string) {
+	gm.enqueue(message{Text: text})
+}
+
+// enqueue はメッセージを待ち行列の末尾に追加する。表示する文字がないメッセージは無視する。
+// 任意のgoroutineから呼び出せる。
+func (gm *Game) enqueue(msg message) {
+	if gm.stripANSI {
+		msg.Text = stripANSI(msg.Text)
+	}
+	if msg.blank() {
+		return
+	}
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	gm.msgQueue = append(gm.msgQueue, msg)
+}
+
+// dequeue は待ち行列の先頭のメッセージを取り出す。空の場合は false を返す。
+func (gm *Game) dequeue() (message, bool) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	if len(gm.msgQueue) == 0 {
+		return message{}, false
+	}
+	msg := gm.msgQueue[0]
+	gm.msgQueue = gm.msgQueue[1:]
+	return msg, true
+}
+
+// peekQueue は待ち行列の先頭のメッセージを取り出さずに返す。空の場合は false を返す。
+func (gm *Game) peekQueue() (message, bool) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	if len(gm.msgQueue) == 0 {
+		return message{}, false
+	}
+	return gm.msgQueue[0], true
+}
+
+// queueLen は待ち行列にあるメッセージの数を返す。
+func (gm *Game) queueLen() int {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+	return len(gm.msgQueue)
+}
+
+// ErrClosed はメインループが終了した後や Close した後の Game を操作したときに返すエラー。
+var ErrClosed = errors.New("mascot: game is closed")
+
+// runOnUpdate は f を Update の中で実行するよう予約し、実行が終わるまで待つ。
+// Game の状態を変更する操作を任意のgoroutineから安全に呼び出すために使う。
+// メインループが終了した後や Close した後は、f を実行せずに ErrClosed を返す。
+func (gm *Game) runOnUpdate(f func()) error {
+	done := make(chan struct{})
+	gm.mu.Lock()
+	gm.tasks = append(gm.tasks, func() {
+		f()
+		close(done)
+	})
+	gm.mu.Unlock()
+	select {
+	case <-done:
+		return nil
+	case <-gm.loopDone:
+		return ErrClosed
+	case <-gm.ctx.Done():
+		return ErrClosed
+	}
+}
+
+// runTasks は runOnUpdate で予約された処理を実行する。
+func (gm *Game) runTasks() {
+	gm.mu.Lock()
+	tasks := gm.tasks
+	gm.tasks = nil
+	gm.mu.Unlock()
+	for _, f := range tasks {
+		f()
+	}
+}
+
+// Clear は表示中のメッセージを消す。任意のgoroutineから呼び出せる。
+func (gm *Game) Clear() {
+	gm.runOnUpdate(gm.clearMessage)
+}
+
+// Quit はアプリケーションを終了する。任意のgoroutineから呼び出せる。
+func (gm *Game) Quit() {
+	gm.runOnUpdate(func() { gm.quit = true })
+}
+
+// Close は入力元を読み込むgoroutineを止め、HTTP サーバーとソケットを閉じる。
+// Game を作り直すライブラリの利用者が、古い Game の goroutine を残さないために使う。
+// 入力元のチャネルは、止めた後も送り手が詰まらないよう読み捨てる。2回目以降の呼び出しは何もせず同じ結果を返す。
+// 任意のgoroutineから呼び出せる。
+func (gm *Game) Close() error {
+	gm.closeOnce.Do(func() {
+		gm.cancel()
+		var errs []error
+		for _, c := range gm.closers {
+			errs = append(errs, c())
+		}
+		gm.closeErr = errors.Join(errs...)
+	})
+	return gm.closeErr
+}
+
+// SetGopherImage はGopher画像を指定パスの画像（PNG・JPEG・GIF）に差し替える。任意のgoroutineから呼び出せる。
+// メインループが終了した後や Close した後は差し替えずに ErrClosed を返す。
+func (gm *Game) SetGopherImage(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read image %s: %w", path, err)
+	}
+	var loadErr error
+	if err := gm.runOnUpdate(func() {
+		frames, err := decodeGopherFrames(data)
+		if err != nil {
+			loadErr = fmt.Errorf("load image %s: %w", path, err)
+			return
+		}
+		gm.setGopherFrames(frames)
+	}); err != nil {
+		return err
+	}
+	return loadErr
+}
+
+// setGopherFrames はGopher画像のフレーム列を差し替え、新しい画像サイズでレイアウトし直す。
+// 差し替えた画像の目の位置やスプライトシートの表情は分からないため、まばたきと表情の切り替えは無効にする。
+func (gm *Game) setGopherFrames(frames []gopherFrame) {
+	gm.gopherFrames = frames
+	gm.gopherImage = frames[0].image
+	gm.frameIndex = 0
+	gm.frameElapsed = 0
+	gm.sprites = nil
+	gm.eyes = nil
+	gm.blinkFrame = 0
+	gm.relayout(strings.Join(gm.layout.lines, "\n"), gm.layout.bubbleStyle)
+}
+
+// nextMessage は表示中のメッセージを置き換えられる場合に限り、次のメッセージを取り出す。
+// 進捗バーを表示中に次の進捗付きのメッセージが届いた場合は、表示時間を待たずに置き換える。
+func (gm *Game) nextMessage() (message, bool) {
+	if gm.browseIndex >= 0 {
+		return message{}, false
+	}
+	if gm.hasMessage && gm.msgTimer > 0 {
+		if next, ok := gm.peekQueue(); !ok || !gm.hasProgress || next.Progress == nil {
+			return message{}, false
+		}
+	}
+	return gm.dequeue()
+}
+
+// bounceFrames は跳ねるアニメーションのフレーム数を返す。
+func bounceFrames() int {
+	return int(bounceSec * float64(ebiten.TPS()))
+}
+
+// bounceOffset は跳ねるアニメーションによる縦方向のずれ(px)を返す。
+// 減衰するサイン波の絶対値を使い、上方向にだけ跳ねる。
+func (gm *Game) bounceOffset() float64 {
+	total := bounceFrames()
+	if gm.bounceTimer <= 0 || total <= 0 {
+		return 0
+	}
+	t := 1 - float64(gm.bounceTimer)/float64(total) // 0→1
+	return -bounceHeight * math.Exp(-5*t) * math.Abs(math.Sin(3*math.Pi*t))
+}
+
+// SetClickThrough はクリック透過モードを切り替える。
+// 有効にするとウィンドウはマウス入力を受け取らず、クリックは下にあるアプリケーションに届く。
+// マウスの透過はデスクトップ環境でのみ機能し、対応していないプラットフォームではドラッグが無効になるだけとなる。
+func (gm *Game) SetClickThrough(enabled bool) {
+	gm.clickThrough = enabled
+	gm.dragging = false
+	ebiten.SetWindowMousePassthrough(enabled)
+}
+
+// relayout はメッセージに合わせてレイアウトを計算し直し、ウィンドウをリサイズする。
+// 配置した角の位置が変わらないようウィンドウ位置を調整したうえで、モニターからはみ出さないよう収める。
+// 吹き出しを下に置く設定の場合や、自動の設定でウィンドウが上にはみ出す場合は、
+// 吹き出しをGopherの下に移してしっぽを上向きにする。
+// ウィンドウの大きさを固定する設定では、ウィンドウの大きさと位置は変えず、その中でレイアウトだけを計算し直す。
+func (gm *Game) relayout(message string, style bubbleStyle) {
+	textW := gm.textWidth(strings.Split(message, "\n"))
+	fixed := gm.layoutCfg.fixedSize()
+	ly, sw, sh := calcLayout(gm.gopherImage.Bounds().Size(), textW, message, gm.hasProgress, gm.historyWidths(), gm.corner, gm.fixedBelow(), gm.layoutCfg)
+	wx, wy := gm.windowPosition()
+	wx, wy = gm.corner.resizedWindowPosition(wx, wy, gm.screenWidth, gm.screenHeight, sw, sh)
+	if !fixed && message != "" && (gm.placement == placementBelow || gm.placement == placementAuto && wy < 0) {
+		// Gopherの画面上の位置を保ったまま、ウィンドウを下に伸ばす
+		gopherScreenY := wy + int(ly.gopherY)
+		ly, sw, sh = calcLayout(gm.gopherImage.Bounds().Size(), textW, message, gm.hasProgress, gm.historyWidths(), gm.corner, true, gm.layoutCfg)
+		wy = gopherScreenY - int(ly.gopherY)
+	}
+	ly.bubbleStyle = style
+	ly.setBubbleOffset(gm.bubbleOffX, gm.bubbleOffY, sw, sh)
+
+	wx, wy = clampWindowPosition(wx, wy, sw, sh, monitorBounds())
+
+	gm.layout = ly
+	gm.screenWidth = sw
+	gm.screenHeight = sh
+	gm.dirty = true
+	if fixed {
+		return
+	}
+	gm.resizeWindow(windowRect{x: wx, y: wy, w: sw, h: sh})
+}
+
+// fixedBelow は、ウィンドウの大きさを固定する設定で吹き出しを下に置く設定なら true を返す。
+// 固定の場合は、メッセージの有無でGopherが動かないよう、メッセージがなくても常に下に置くレイアウトにする。
+func (gm *Game) fixedBelow() bool {
+	return gm.layoutCfg.fixedSize() && gm.placement == placementBelow
+}
+
+// --- 描画 ---
+
+// showMessage はメッセージを折り返してレイアウトを計算し直し、表示を開始する。Update の中から呼び出す。
+func (gm *Game) showMessage(msg message) {
+	// 進捗バーの更新は、履歴に残さず表示中の吹き出しをその場で置き換える
+	inPlace := gm.hasMessage && gm.hasProgress && msg.Progress != nil
+	if !inPlace {
+		gm.archiveMessage()
+		if gm.browseIndex < 0 && !gm.noticing {
+			gm.recordRecent(msg)
+		}
+	}
+	gm.hasProgress = msg.Progress != nil
+	if gm.hasProgress {
+		gm.progressTarget = min(max(*msg.Progress, 0), 1)
+		if !inPlace {
+			gm.progress = 0
+		}
+	}
+	wrapped := gm.setMessage(msg)
+	if gm.speaker != nil && !inPlace {
+		gm.speaker.speak(gm.messageText)
+	}
+	gm.relayout(wrapped, parseBubbleStyle(msg.Style))
+	gm.scrollY = 0
+	gm.scrollManual = false
+
+	if !gm.hasMessage {
+		gm.bounceTimer = bounceFrames()
+		gm.playSound()
+	}
+	gm.hasMessage = true
+	// 表示時間が0（消えない設定）の場合、msgTimer は0のままカウントダウンされない。
+	gm.msgTimer = msg.frames(gm.duration, wrapped)
+	if gm.sticky {
+		gm.msgTimer = 0
+	}
+	if gm.logger != nil {
+		gm.logger.Info("show message",
+			"text", gm.messageText,
+			"duration", time.Duration(float64(gm.msgTimer)/float64(ebiten.TPS())*float64(time.Second)),
+			"lines", len(gm.layout.lines),
+		)
+	}
+	// 表示途中のメッセージがあってもタイプライター表示は最初からやり直す
+	gm.revealedChars = 0
+	gm.revealAcc = 0
+	gm.messageCPS = revealRate(gm.layout.charCount(), gm.msgTimer, gm.revealCPS, gm.revealMaxCPS)
+	if gm.revealCPS <= 0 {
+		gm.revealedChars = gm.layout.charCount()
+	}
+	if inPlace {
+		gm.revealedChars = gm.layout.charCount()
+	} else {
+		gm.bubbleAlpha = 0
+	}
+	gm.setExpression(gm.messageExpression(gm.messageText))
+}
+
+// setMessage はメッセージのテキスト・リンク・文字色・揃え方・画像を設定し、折り返したテキストを返す。
+// レイアウトは計算し直さない。
+func (gm *Game) setMessage(msg message) string {
+	src := expandTabs(lineBreaks.Replace(strings.ReplaceAll(msg.Text, "\\n", "\n")), gm.tabWidth)
+	plain, styles := parseMarkup(parseANSIColors(src))
+	sc := detectScript(plain)
+	if sc == scriptCJK && gm.cjkFace != nil {
+		markCJK(styles)
+	}
+	gm.messageText = plain
+	gm.links = markLinks(plain, styles)
+	wr := gm.wrapCached(src, plain, styles, sc)
+	wrapped := wr.wrapped
+	gm.textStyles = wr.styles
+	gm.textColor = color.Black
+	if c, err := parseHexColor(msg.Color); msg.Color != "" && err == nil {
+		gm.textColor = c
+	}
+	gm.textAlign = gm.defaultAlign
+	if a, ok := parseTextAlign(msg.Align); ok {
+		gm.textAlign = a
+	}
+	gm.messageImage = gm.namedImages[msg.Image]
+	return wrapped
+}
+
+// revealRate は chars 文字を、表示時間 frames の最初の revealShare の間に表示し終える速度（文字/秒）を返す。
+// 速度は minCPS 以上 maxCPS 以下に収める。表示時間が0（消えない設定）の場合は minCPS を返す。
+func revealRate(chars, frames int, minCPS, maxCPS float64) float64 {
+	if frames <= 0 {
+		return minCPS
+	}
+	sec := float64(frames) / float64(ebiten.TPS()) * revealShare
+	return min(max(float64(chars)/sec, minCPS), maxCPS)
+}
+
+func (gm *Game) Update() error {
+	gm.runTasks()
+	if gm.quit {
+		return ebiten.Termination
+	}
+	if err := gm.updateDeviceScale(); err != nil {
+		return err
+	}
+
+	// 表示時間などは取り出した時点の TPS でフレーム数に換算するため、省電力モードで下げた TPS は先に戻しておく
+	gm.updatePower()
+	// 表示中のメッセージが終わっていれば待ち行列から次のメッセージを取り出す。
+	// 表示時間0（消えない設定）のメッセージは、次のメッセージが届いた時点で置き換える。
+	if msg, ok := gm.nextMessage(); ok {
+		gm.showMessage(msg)
+	}
+	// 入力が終わり、最後のメッセージも消えたら終了する
+	if gm.inputDone && !gm.hasMessage {
+		return ebiten.Termination
+	}
+
+	// タイプライター表示の進行
+	if gm.hasMessage && gm.revealedChars < gm.layout.charCount() {
+		gm.revealAcc += gm.messageCPS / float64(ebiten.TPS())
+		n := int(gm.revealAcc)
+		gm.revealedChars += n
+		gm.revealAcc -= float64(n)
+	}
+
+	gm.updateResize()
+	gm.updateFade()
+	gm.updateGopherFrame()
+	gm.updateBlink()
+	gm.updateIdle()
+	gm.updateHistory()
+	gm.updateProgress()
+	gm.updateFocus()
+	gm.updateRedraw()
+	if gm.bounceTimer > 0 {
+		gm.bounceTimer--
+	}
+
+	cx, cy := gm.cursorPosition()
+	gm.updateScroll(cx, cy)
+	gm.updateBrowse(cx, cy)
+	gm.updateLean(cx, cy)
+
+	// メッセージ表示タイマーのカウントダウン。
+	// 読んでいる途中で消えないよう、カーソルが吹き出しの上にある間と、過去のメッセージを遡っている間は止める
+	if gm.hasMessage && gm.msgTimer > 0 && gm.browseIndex < 0 && (gm.dragging || !gm.hitBubble(cx, cy)) {
+		gm.msgTimer--
+		if gm.msgTimer <= 0 {
+			// 次のメッセージが待っていれば、メッセージなしのレイアウトを挟まずに切り替え、
+			// ウィンドウが縮んですぐ広がるちらつきを防ぐ
+			if gm.noticing {
+				gm.clearMessage()
+			} else if msg, ok := gm.dequeue(); ok {
+				gm.archiveMessage()
+				gm.hasMessage = false
+				gm.showMessage(msg)
+			} else {
+				gm.clearMessage()
+			}
+		}
+	}
+
+	// 終了時に保存できるよう最新のウィンドウ位置を覚えておく
+	gm.windowX, gm.windowY = ebiten.WindowPosition()
+
+	// 右クリックメニュー（メニューを閉じたクリックではドラッグを始めない）
+	if !gm.clickThrough && gm.updateMenu(cx, cy) {
+		return ebiten.Termination
+	}
+
+	// Mキーで通知音のミュートを切り替える
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		gm.toggleMute()
+	}
+
+	// Escキーでメニューを閉じる。メニューが開いていなければメッセージを消す
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		if gm.menuOpen {
+			gm.menuOpen = false
+		} else {
+			gm.clearMessage()
+		}
+	}
+
+	// 吹き出しをクリックしたらメッセージを消し、ドラッグしたら吹き出しを動かす
+	// （Gopherと重なる部分はGopherのドラッグを優先する）
+	if !gm.clickThrough && !gm.suppressDrag && gm.hasMessage &&
+		inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) &&
+		gm.hitBubble(cx, cy) && !gm.hitGopher(cx, cy) {
+		gm.bubblePressed = true
+		gm.dragStartX, gm.dragStartY = cx, cy
+		gm.bubbleStartX, gm.bubbleStartY = gm.bubbleOffX, gm.bubbleOffY
+		gm.suppressDrag = true
+	}
+	if gm.bubblePressed {
+		gm.updateBubbleDrag(cx, cy)
+	}
+
+	if !gm.clickThrough && inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) && gm.hitGopher(cx, cy) {
+		gm.handleGopherClick()
+	}
+	if gm.updateTouchDrag() {
+		// 指でドラッグしている間はマウスのドラッグを扱わない
+	} else if !gm.clickThrough && !gm.suppressDrag && ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		if !gm.dragging {
+			// Gopherの矩形内をクリックしたらドラッグ開始
+			if gm.hitGopher(cx, cy) {
+				gm.startDrag(cx, cy)
+			}
+		} else {
+			gm.moveDrag(cx, cy)
+		}
+	} else if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		gm.endDrag()
+		gm.suppressDrag = false
+	}
+	gm.updateCursorShape(cx, cy)
+
+	return nil
+}
+
+// updateCursorShape は、ドラッグできることが分かるよう、Gopherの上とドラッグ中はカーソルを移動の形にする。
+// 形が変わるときだけ ebiten.SetCursorShape を呼ぶ。
+func (gm *Game) updateCursorShape(cx, cy int) {
+	shape := ebiten.CursorShapeDefault
+	if !gm.clickThrough && (gm.dragging || (!gm.suppressDrag && gm.hitGopher(cx, cy))) {
+		shape = ebiten.CursorShapeMove
+	}
+	if shape != gm.cursorShape {
+		gm.cursorShape = shape
+		ebiten.SetCursorShape(shape)
+	}
+}
+
+// startDrag は (cx, cy) からGopherのドラッグを始める。
+func (gm *Game) startDrag(cx, cy int) {
+	gm.finishResize()
+	gm.dragging = true
+	gm.dragStartX = cx
+	gm.dragStartY = cy
+}
+
+// moveDrag はドラッグ中のカーソルの位置に合わせてウィンドウを移動する。
+func (gm *Game) moveDrag(cx, cy int) {
+	dx := cx - gm.dragStartX
+	dy := cy - gm.dragStartY
+	if dx != 0 || dy != 0 {
+		// Gopherを見失わないよう、Gopherがモニター内に残る範囲で動かす
+		wx, wy := ebiten.WindowPosition()
+		ebiten.SetWindowPosition(clampDragPosition(wx+dx, wy+dy, gm.gopherRect(), monitorBounds()))
+	}
+}
+
+// endDrag はGopherのドラッグを終える。ドラッグで移動したら、モニターの端の近くなら端に吸着させて位置を保存する。
+func (gm *Game) endDrag() {
+	if gm.dragging {
+		gm.snapToEdge()
+		gm.saveState()
+	}
+	gm.dragging = false
+}
+
+// snapToEdge はウィンドウがモニターの端から snapPx 以内にあれば、端にぴったり合わせる。
+func (gm *Game) snapToEdge() {
+	if gm.snapPx <= 0 {
+		return
+	}
+	wx, wy := ebiten.WindowPosition()
+	x, y := snapWindowPosition(wx, wy, gm.screenWidth, gm.screenHeight, monitorBounds(), gm.snapPx)
+	if x != wx || y != wy {
+		ebiten.SetWindowPosition(x, y)
+	}
+	gm.windowX, gm.windowY = x, y
+}
+
+// hitGopher は座標がGopherの矩形内にあるかを返す。
+func (gm *Game) hitGopher(x, y int) bool {
+	ly := gm.layout
+	w := float64(gm.gopherImage.Bounds().Dx()) * ly.gopherScale
+	h := float64(gm.gopherImage.Bounds().Dy()) * ly.gopherScale
+	return float64(x) >= ly.gopherX && float64(x) <= ly.gopherX+w &&
+		float64(y) >= ly.gopherY && float64(y) <= ly.gopherY+h
+}
+
+// gopherRect はウィンドウ内のGopherの矩形を返す。
+func (gm *Game) gopherRect() image.Rectangle {
+	ly := gm.layout
+	w := float64(gm.gopherImage.Bounds().Dx()) * ly.gopherScale
+	h := float64(gm.gopherImage.Bounds().Dy()) * ly.gopherScale
+	return image.Rect(int(ly.gopherX), int(ly.gopherY), int(math.Ceil(ly.gopherX+w)), int(math.Ceil(ly.gopherY+h)))
+}
+
+// hitBubble は座標が吹き出しの矩形内にあるかを返す。
+func (gm *Game) hitBubble(x, y int) bool {
+	ly := gm.layout
+	fx, fy := float32(x), float32(y)
+	return fx >= ly.bubbleX && fx <= ly.bubbleX+ly.bubbleW &&
+		fy >= ly.bubbleY && fy <= ly.bubbleY+ly.bubbleH
+}
+
+// clearMessage は表示中のメッセージを消し、メッセージなしのレイアウトに戻す。
+func (gm *Game) clearMessage() {
+	if !gm.hasMessage {
+		return
+	}
+	if gm.noticing && gm.noticeLive {
+		gm.resumeFromNotice()
+		return
+	}
+	gm.archiveMessage()
+	gm.noticing = false
+	gm.hasMessage = false
+	gm.hasProgress = false
+	gm.msgTimer = 0
+	gm.messageImage = nil
+	if gm.speaker != nil {
+		gm.speaker.stop()
+	}
+	gm.setExpression(expressionNeutral)
+	gm.relayout("", styleSpeech)
+}
+
+// updateGopherFrame はアニメーションの経過時間を進め、表示するGopherのフレームを切り替える。
+func (gm *Game) updateGopherFrame() {
+	if len(gm.gopherFrames) <= 1 {
+		return
+	}
+	gm.frameElapsed += 1 / float64(ebiten.TPS())
+	for gm.frameElapsed >= gm.gopherFrames[gm.frameIndex].delay {
+		gm.frameElapsed -= gm.gopherFrames[gm.frameIndex].delay
+		gm.frameIndex = (gm.frameIndex + 1) % len(gm.gopherFrames)
+	}
+}
+
+// updateFade は吹き出しの不透明度を更新する。
+// 表示開始から fadeSec かけて 0→1 に上げ、表示終了前の fadeSec で 1→0 に下げる。
+func (gm *Game) updateFade() {
+	if !gm.hasMessage {
+		gm.bubbleAlpha = 0
+		return
+	}
+	fadeFrames := gm.fadeSec * float64(ebiten.TPS())
+	if fadeFrames < 1 {
+		gm.bubbleAlpha = 1
+		return
+	}
+	gm.bubbleAlpha = math.Min(gm.bubbleAlpha+1/fadeFrames, 1)
+	if gm.msgTimer > 0 {
+		gm.bubbleAlpha = math.Min(gm.bubbleAlpha, float64(gm.msgTimer)/fadeFrames)
+	}
+}
+
+func (gm *Game) Draw(screen *ebiten.Image) {
+	if !gm.needsRedraw(screen) {
+		return
+	}
+	gm.clearScreen(screen)
+	dst := screen
+	opacity := gm.opacity * gm.focusDim
+	if opacity < 1 {
+		// Gopherと吹き出しの重なりが透けないよう、一度不透明で描画してから全体に不透明度を掛けて合成する
+		dst = ensureLayer(&gm.opacityLayer, screen.Bounds().Dx(), screen.Bounds().Dy())
+		dst.Clear()
+	}
+	if gm.resize != nil {
+		gm.drawResizing(dst, gm.drawContent)
+	} else {
+		gm.drawContent(dst)
+	}
+	if dst != screen {
+		op := &ebiten.DrawImageOptions{}
+		op.ColorScale.ScaleAlpha(float32(opacity))
+		screen.DrawImage(dst, op)
+	}
+}
+
+// clearScreen は画面を透明に消す。背景色が指定されていれば、その色で塗りつぶす。
+func (gm *Game) clearScreen(screen *ebiten.Image) {
+	if gm.background == nil {
+		screen.Clear()
+		return
+	}
+	screen.Fill(gm.background)
+}
+
+// drawContent は吹き出し・Gopher・メニューを描画する。
+func (gm *Game) drawContent(screen *ebiten.Image) {
+	ly := gm.layout
+
+	// ドラッグ中はフェードに関係なく吹き出しを即座に隠す
+	if !gm.dragging {
+		gm.drawHistory(screen, ly)
+	}
+	if !gm.dragging && gm.hasMessage && gm.bubbleAlpha > 0 {
+		// 吹き出しは塗りと枠線が重なるため、一度不透明で描画してから全体に不透明度を掛けて合成する
+		layer := ensureLayer(&gm.bubbleLayer, screen.Bounds().Dx(), screen.Bounds().Dy())
+		layer.Clear()
+		gm.drawBubble(layer, ly)
+		gm.drawText(layer, ly)
+		gm.drawProgress(layer, ly)
+
+		op := &ebiten.DrawImageOptions{}
+		op.ColorScale.ScaleAlpha(float32(gm.bubbleAlpha))
+		screen.DrawImage(layer, op)
+	}
+
+	gm.drawGopher(screen, ly)
+
+	if gm.menuOpen {
+		gm.drawMenu(screen)
+	}
+}
+
+// ensureLayer は *layer を w×h のオフスクリーン画像として返す。サイズが変わった場合は作り直す。
+func ensureLayer(layer **ebiten.Image, w, h int) *ebiten.Image {
+	if *layer != nil {
+		if b := (*layer).Bounds(); b.Dx() == w && b.Dy() == h {
+			return *layer
+		}
+		(*layer).Deallocate()
+	}
+	*layer = ebiten.NewImage(w, h)
+	return *layer
+}
+
+// drawBubble は角丸の吹き出し本体としっぽを描画する。
+func (gm *Game) drawBubble(screen *ebiten.Image, ly layout) {
+	bx, by, bw, bh := ly.bubbleX, ly.bubbleY, ly.bubbleW, ly.bubbleH
+	r := ly.bubbleRadius
+
+	bp := gm.bubblePath(bx, by, bw, bh, r)
+
+	// 描画順序: 影 → 吹き出し塗り → しっぽ塗り → 吹き出し枠 → しっぽ枠
+	tail := newBubbleTail(ly, gm.layoutCfg, float32(gm.deviceScale), gm.antiAlias, gm.strokeStyle)
+
+	gm.drawShadow(screen, bp)
+	s := float32(gm.deviceScale)
+	vector.FillPath(screen, scalePath(bp, s), nil, &vector.DrawPathOptions{
+		AntiAlias: gm.antiAlias, ColorScale: colorScale(gm.bubbleFill),
+	})
+	if tail != nil {
+		tail.fill(screen, gm.bubbleFill)
+	}
+
+	stroke := gm.strokeStyle.options(float32(gm.layoutCfg.StrokeWidth)*s, vector.LineCapButt, vector.LineJoinMiter)
+	vector.StrokePath(screen, scalePath(bp, s), stroke, &vector.DrawPathOptions{
+		AntiAlias: gm.antiAlias, ColorScale: colorScale(gm.bubbleStroke),
+	})
+	if tail != nil {
+		tail.stroke(screen, gm.bubbleFill, gm.bubbleStroke)
+	}
+}
+
+// roundRectPath は半径 r の角丸四角形のパスを返す。
+func roundRectPath(x, y, w, h, r float32) *vector.Path {
+	var p vector.Path
+	p.MoveTo(x+r, y)
+	p.LineTo(x+w-r, y)
+	p.ArcTo(x+w, y, x+w, y+r, r)
+	p.LineTo(x+w, y+h-r)
+	p.ArcTo(x+w, y+h, x+w-r, y+h, r)
+	p.LineTo(x+r, y+h)
+	p.ArcTo(x, y+h, x, y+h-r, r)
+	p.LineTo(x, y+r)
+	p.ArcTo(x, y, x+r, y, r)
+	p.Close()
+	return &p
+}
+
+// bubbleTail は吹き出しのしっぽの描画方法。吹き出しのスタイルごとに実装を切り替える。
+type bubbleTail interface {
+	// fill はしっぽを塗る。吹き出しの枠線より前に呼ばれる。
+	fill(dst *ebiten.Image, fillColor color.Color)
+	// stroke はしっぽの枠線を描く。吹き出しの枠線より後に呼ばれる。
+	stroke(dst *ebiten.Image, fillColor, strokeColor color.Color)
+}
+
+// newBubbleTail はレイアウトのスタイルとしっぽ位置に応じた bubbleTail を返す。しっぽの大きさと枠線の太さは cfg から読む。
+// しっぽは論理座標で計算し、描画時にデバイススケール s 倍する。aa はアンチエイリアスをかけるか、st は枠線の端と角の形。
+// しっぽの幅か高さが0なら nil を返す。
+func newBubbleTail(ly layout, cfg LayoutConfig, s float32, aa bool, st strokeStyle) bubbleTail {
+	if cfg.TailWidth <= 0 || cfg.TailLength <= 0 {
+		return nil
+	}
+	w, hw, l := float32(cfg.StrokeWidth), float32(cfg.TailWidth)/2, float32(cfg.TailLength)
+	// Gopherが左寄りならしっぽを左右反転する
+	var m float32 = 1
+	if ly.tailDir == tailLeft {
+		m = -1
+	}
+	x, y := ly.tailBase()
+	// 吹き出しがGopherの下にある場合はしっぽを上下反転する
+	var v float32 = 1
+	if ly.bubbleBelow {
+		v = -1
+	}
+	// 太い枠線で内側に寄せた輪郭に付け根を合わせる
+	y -= strokeInset(cfg.StrokeWidth) * v
+
+	// 先端は基部から斜め下に出す。吹き出しを動かした場合はGopherの頭に向ける
+	tx, ty := x-l*3/4*m, y+l*v
+	if ly.tailAimed {
+		tx, ty = ly.tailTipX, ly.tailTipY
+		if tx > x {
+			m = -1
+		} else {
+			m = 1
+		}
+	}
+
+	if ly.bubbleStyle == styleThink {
+		return thinkTail{x: x, y: y, tx: tx, ty: ty, hw: hw, w: w, s: s, aa: aa}
+	}
+	return speechTail{x: x, y: y, tx: tx, ty: ty, m: m, hw: hw, w: w, s: s, aa: aa, st: st}
+}
+
+// speechTail は吹き出しから小さく突き出る曲線のしっぽ。
+type speechTail struct {
+	x, y   float32     // 基部の中心
+	tx, ty float32     // 先端
+	m      float32     // 1で左向き、-1で右向き
+	hw     float32     // 付け根の幅の半分
+	w      float32     // 枠線の太さ
+	s      float32     // デバイススケール
+	aa     bool        // アンチエイリアスをかけるか
+	st     strokeStyle // 枠線の端と角の形
+}
+
+func (t speechTail) curve(p *vector.Path) {
+	tbx, tby, m := t.x, t.y, t.m
+	dx, dy := t.tx-tbx, t.ty-tby // 基部から先端まで
+
+	// 制御点は基部から先端までの比率で置き、先端の向きが変わっても同じ形を保つ
+	p.MoveTo(tbx-t.hw*m, tby)
+	p.QuadTo(tbx+dx*8/15, tby+dy*8/20, t.tx, t.ty)
+	p.QuadTo(tbx-dx*2/15, tby+dy*12/20, tbx+t.hw*m, tby)
+}
+
+func (t speechTail) fill(dst *ebiten.Image, fillColor color.Color) {
+	var tp vector.Path
+	t.curve(&tp)
+	tp.Close()
+	vector.FillPath(dst, scalePath(&tp, t.s), nil, &vector.DrawPathOptions{
+		AntiAlias: t.aa, ColorScale: colorScale(fillColor),
+	})
+}
+
+func (t speechTail) stroke(dst *ebiten.Image, fillColor, strokeColor color.Color) {
+	// 吹き出しとしっぽの境界の枠線を塗り色で上書き
+	vector.FillRect(dst, (t.x-t.hw+1)*t.s, (t.y-t.w/2-1)*t.s, (t.hw-1)*2*t.s, (t.w+2)*t.s, fillColor, t.aa)
+
+	// しっぽの外側の曲線のみ描画
+	var to vector.Path
+	t.curve(&to)
+	vector.StrokePath(dst, scalePath(&to, t.s), t.st.options(t.w*t.s, vector.LineCapRound, vector.LineJoinRound), &vector.DrawPathOptions{
+		AntiAlias: t.aa, ColorScale: colorScale(strokeColor),
+	})
+}
+
+// thinkTail は吹き出しからGopherへ向かって小さくなる円を並べた、考え事用のしっぽ。
+type thinkTail struct {
+	x, y   float32 // 基部の中心
+	tx, ty float32 // 先端
+	hw     float32 // 付け根の幅の半分。円の大きさの基準にする
+	w      float32 // 枠線の太さ
+	s      float32 // デバイススケール
+	aa     bool    // アンチエイリアスをかけるか
+}
+
+// circles は円の中心と半径を吹き出しに近い順に返す。
+func (t thinkTail) circles() [3][3]float32 {
+	dx, dy := t.tx-t.x, t.ty-t.y
+	r := t.hw / 10
+	return [3][3]float32{
+		{t.x + dx*3/15, t.y + dy*8/20, 5 * r},
+		{t.x + dx*9/15, t.y + dy*16/20, 3.5 * r},
+		{t.x + dx*14/15, t.y + dy*22/20, 2 * r},
+	}
+}
+
+func (t thinkTail) fill(dst *ebiten.Image, fillColor color.Color) {
+	for _, c := range t.circles() {
+		vector.FillCircle(dst, c[0]*t.s, c[1]*t.s, c[2]*t.s, fillColor, t.aa)
+	}
+}
+
+func (t thinkTail) stroke(dst *ebiten.Image, _, strokeColor color.Color) {
+	for _, c := range t.circles() {
+		vector.StrokeCircle(dst, c[0]*t.s, c[1]*t.s, c[2]*t.s, t.w*t.s, strokeColor, t.aa)
+	}
+}
+
+// textView は吹き出し内でテキストを表示する範囲（論理座標）。
+type textView struct {
+	x, w        float64 // 行の左端と幅
+	top, bottom float64 // 表示範囲の上端と下端。スクロールする場合、範囲外の行は切り取る
+}
+
+// placedLine は吹き出し内に配置した1行。
+type placedLine struct {
+	x, y float64     // 描画位置（論理座標）。y は行の上端
+	runs []styledRun // 表示済みの文字を見た目の順序に並べたスタイル付きの並び
+	urls []string    // runs のうちリンクの並びの URL。リンクでなければ空文字列
+	code bool        // コードブロックの行か
+}
+
+// placeText は吹き出し内の各行の描画位置を求める。描画とリンクのクリック判定で共有する。
+// タイプライター表示でまだ表示していない行は含めない。
+func (gm *Game) placeText(ly layout) (textView, []placedLine) {
+	cfg := gm.layoutCfg
+	view := textView{
+		x:      float64(ly.bubbleX) + cfg.BubblePadX/2 - 2,
+		w:      float64(ly.bubbleW) - cfg.BubblePadX,
+		top:    float64(ly.bubbleY),
+		bottom: float64(ly.bubbleY + ly.bubbleH - ly.progressH),
+	}
+	// 1行目の大文字の上端から最終行のディセンダーの下端までを、吹き出しの上下中央に置く。
+	// text.Draw の描画位置は行の上端で、ベースラインはそこから HAscent 下にある
+	m := gm.fontFace.Metrics()
+	inkH := float64(len(ly.lines)-1)*ly.lineHeight + m.CapHeight + m.HDescent
+	top := float64(ly.bubbleY) + (float64(ly.bubbleH-ly.progressH)-inkH)/2
+	if ly.textH > ly.textViewH {
+		// スクロールする場合は表示範囲の上端から並べる
+		view.top = float64(ly.bubbleY) + cfg.BubblePadY/2
+		view.bottom = view.top + ly.textViewH
+		top = view.top + (ly.lineHeight-m.CapHeight-m.HDescent)/2 - gm.scrollY
+	}
+	y := top + m.CapHeight - m.HAscent
+
+	// タイプライター表示：先頭から revealedChars 文字分だけ配置する
+	var lines []placedLine
+	remaining := gm.revealedChars
+	for i, line := range ly.lines {
+		if remaining <= 0 {
+			break
+		}
+		runes := []rune(line)
+		var styles []textStyle
+		if i < len(gm.textStyles) {
+			styles = gm.textStyles[i]
+		}
+		shown := runes[:min(len(runes), remaining)]
+		remaining -= len(runes)
+
+		// 右から左に書く行は見た目の順序に並べ替え、揃え方の左右を反転する
+		rtl := isRTLLine(runes)
+		align := gm.textAlign
+		if rtl && align != alignCenter {
+			align = alignRight - align
+		}
+		vr, vs := visualOrder(shown, styles, rtl)
+		runs := styledRuns(vr, vs)
+
+		// 揃え位置は行全体の幅で決め、タイプライター表示中も文字が動かないようにする。
+		// 右から左に書く行は右端を固定し、左に向かって文字を増やす
+		lineW := gm.measureRuns(styledRuns(runes, styles))
+		dx := 0.0
+		switch align {
+		case alignCenter:
+			dx = (view.w - lineW) / 2
+		case alignRight:
+			dx = view.w - lineW
+		}
+		if rtl {
+			dx += lineW - gm.measureRuns(runs)
+		}
+
+		lines = append(lines, placedLine{
+			x:    view.x + dx,
+			y:    y + float64(i)*ly.lineHeight,
+			runs: runs,
+			code: styleAt(styles, 0)&textCode != 0,
+		})
+	}
+	assignLinks(lines, gm.links)
+	return view, lines
+}
+
+// drawText は吹き出し内にメッセージを描画する。
+func (gm *Game) drawText(screen *ebiten.Image, ly layout) {
+	view, lines := gm.placeText(ly)
+	if ly.textH > ly.textViewH {
+		s := gm.deviceScale
+		r := image.Rect(int(float64(ly.bubbleX)*s), int(view.top*s), int(float64(ly.bubbleX+ly.bubbleW)*s), int(view.bottom*s))
+		screen = screen.SubImage(r).(*ebiten.Image)
+	}
+	m := gm.fontFace.Metrics()
+	for _, pl := range lines {
+		if pl.code {
+			drawCodeBackground(screen, view.x, pl.y+m.HAscent-m.CapHeight-gm.layoutCfg.LineSpacing, view.w, ly.lineHeight, gm.deviceScale)
+		}
+		gm.drawRuns(screen, pl.runs, pl.x, pl.y, colorScale(gm.textColor))
+	}
+}
+
+// drawGopher はGopher画像を描画する。
+// 跳ねるアニメーションの縦方向のずれは描画時にだけ加え、レイアウトとドラッグ判定には影響させない。
+func (gm *Game) drawGopher(screen *ebiten.Image, ly layout) {
+	ly.gopherY += gm.bounceOffset()
+	ly.gopherX += gm.leanX
+	ly.gopherY += gm.leanY
+
+	if gm.messageImage != nil {
+		gm.drawMessageImage(screen, ly)
+		return
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(ly.gopherScale, ly.gopherScale)
+	op.GeoM.Translate(ly.gopherX, ly.gopherY)
+	op.GeoM.Scale(gm.deviceScale, gm.deviceScale)
+	screen.DrawImage(gm.currentGopherImage(), op)
+
+	if gm.blinkFrame > 0 && !gm.hasBlinkExpression() {
+		gm.drawEyelids(screen, ly)
+	}
+}
+
+// currentGopherImage は描画するGopher画像を返す。
+// スプライトシートがあれば表情のフレームを、なければアニメーションの現在のフレームを返す。
+func (gm *Game) currentGopherImage() *ebiten.Image {
+	if gm.sprites != nil {
+		if gm.blinkFrame > 0 && gm.hasBlinkExpression() {
+			return gm.sprites.frame(expressionBlink)
+		}
+		return gm.sprites.frame(gm.expression)
+	}
+	return gm.gopherFrames[gm.frameIndex].image
+}
+
+// colorScale は指定色の ColorScale を返す。
+func colorScale(c color.Color) ebiten.ColorScale {
+	var cs ebiten.ColorScale
+	cs.ScaleWithColor(c)
+	return cs
+}
+
+func (gm *Game) Layout(_, _ int) (int, int) {
+	if gm.resize != nil {
+		r := gm.resize.current()
+		return gm.physicalSize(r.w, r.h)
+	}
+	return gm.physicalSize(gm.screenWidth, gm.screenHeight)
+}
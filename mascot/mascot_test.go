@@ -0,0 +1,270 @@
+package mascot
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+)
+
+func TestRevealFinishesEarly(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Input = nil
+	opts.Duration = DisplayDuration{Base: 10}
+	gm := newTestGame(t, opts)
+	gm.Say(strings.Repeat("abcdefghij", 10))
+	updateFrames(t, gm, 1)
+	total := gm.layout.charCount()
+	if total != 100 {
+		t.Fatalf("charCount() = %d, want 100", total)
+	}
+
+	// 10秒の表示時間のうち最初の3秒で表示し終える
+	updateFrames(t, gm, 2*ebiten.DefaultTPS)
+	if gm.revealedChars >= total {
+		t.Errorf("revealed all %d characters within 2s, want the reveal to take about 3s", total)
+	}
+	updateFrames(t, gm, ebiten.DefaultTPS+2)
+	if gm.revealedChars != total {
+		t.Errorf("revealedChars = %d after 3s, want %d", gm.revealedChars, total)
+	}
+}
+
+func TestDisplayDurationMax(t *testing.T) {
+	huge := strings.Repeat("a", 100000)
+	d := DisplayDuration{Base: 2, PerChar: 0.25, Max: 30}
+	if got, want := d.frames(huge), 30*ebiten.TPS(); got != want {
+		t.Errorf("frames of a huge message = %d, want %d", got, want)
+	}
+	if got, want := d.frames("hi"), 5*ebiten.TPS()/2; got != want {
+		t.Errorf("frames of a short message = %d, want %d", got, want)
+	}
+	// Max が 0 なら上限なし
+	d.Max = 0
+	if got, want := d.frames(huge), 25002*ebiten.TPS(); got != want {
+		t.Errorf("frames without a maximum = %d, want %d", got, want)
+	}
+}
+
+func TestFixedSize(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Input = nil
+	opts.FixedSize = "400x300"
+	opts.Duration = DisplayDuration{} // 次のメッセージまで消さない
+	gm := newTestGame(t, opts)
+	w, h := gm.Layout(0, 0)
+	if wantW, wantH := gm.physicalSize(400, 300); w != wantW || h != wantH {
+		t.Fatalf("Layout = %dx%d, want %dx%d", w, h, wantW, wantH)
+	}
+
+	for _, msg := range []string{"hi", strings.Repeat("a long message that needs many lines ", 20)} {
+		gm.Say(msg)
+		// サイズ変更のアニメーションがあれば終わるまで進める
+		updateFrames(t, gm, ebiten.DefaultTPS)
+		if gotW, gotH := gm.Layout(0, 0); gotW != w || gotH != h {
+			t.Errorf("Layout after %.20q = %dx%d, want %dx%d", msg, gotW, gotH, w, h)
+		}
+	}
+}
+
+func TestStickyMessage(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Input = nil
+	opts.Duration = DisplayDuration{Base: 0.1}
+	gm := newTestGame(t, opts)
+	gm.enqueue(parseMessage(`{"text":"keep","sticky":true}`))
+
+	// 表示時間の何倍も経っても消えない
+	updateFrames(t, gm, 50*gm.duration.frames("keep"))
+	if !gm.hasMessage || gm.messageText != "keep" {
+		t.Fatalf("hasMessage = %v, messageText = %q, want the sticky message shown", gm.hasMessage, gm.messageText)
+	}
+
+	gm.clearMessage()
+	updateFrames(t, gm, 1)
+	if gm.hasMessage {
+		t.Error("sticky message is still shown after clearing it")
+	}
+}
+
+func TestFallbackFont(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "goregular.ttf")
+	if err := os.WriteFile(path, goregular.TTF, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fonts, err := loadFonts("", []string{path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fonts) != 2 {
+		t.Fatalf("loaded %d fonts, want the default and the fallback", len(fonts))
+	}
+
+	// 既定のフォントになく、フォールバックのフォントにある記号を探す
+	var buf sfnt.Buffer
+	hasGlyph := func(f *sfnt.Font, r rune) bool {
+		i, err := f.GlyphIndex(&buf, r)
+		return err == nil && i != 0
+	}
+	var sym rune
+	for _, r := range "♠♣♥♦♪♫☺☻◊∂∆∏∑√∞≈≠≤≥" {
+		if !hasGlyph(fonts[0], r) && hasGlyph(fonts[1], r) {
+			sym = r
+			break
+		}
+	}
+	if sym == 0 {
+		t.Skip("no symbol missing from the default font but present in the fallback font")
+	}
+
+	face, err := newFontFaces(fonts, defaultFontSize, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fallback, err := newFontFaces(fonts[1:], defaultFontSize, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	latin := measureText(face, "Hello ")
+	mixed := measureText(face, "Hello "+string(sym))
+	if latin <= 0 {
+		t.Fatalf("width of %q = %v, want > 0", "Hello ", latin)
+	}
+	// 記号はフォールバックのフォントのグリフの幅で計測され、豆腐や幅0にならない
+	want := measureText(fallback, string(sym))
+	if got := mixed - latin; want <= 0 || math.Abs(got-want) > 1 {
+		t.Errorf("width of %q = %v, want about %v from the fallback font", string(sym), got, want)
+	}
+}
+
+func TestSetAfterClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gopher.png")
+	if err := os.WriteFile(path, gopherPNG, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		close func(gm *Game)
+	}{
+		// Run のメインループが終了した状態にする
+		{"main loop ended", func(gm *Game) { close(gm.loopDone) }},
+		{"Close", func(gm *Game) { gm.Close() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm := newTestGame(t, DefaultOptions())
+			tt.close(gm)
+			if err := gm.SetGopherImage(path); !errors.Is(err, ErrClosed) {
+				t.Errorf("SetGopherImage = %v, want ErrClosed", err)
+			}
+			if err := gm.SetExpression("happy"); !errors.Is(err, ErrClosed) {
+				t.Errorf("SetExpression = %v, want ErrClosed", err)
+			}
+		})
+	}
+}
+
+func TestLogMessage(t *testing.T) {
+	var buf bytes.Buffer
+	opts := DefaultOptions()
+	opts.Input = nil
+	opts.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+	opts.Duration = DisplayDuration{Base: 2}
+	gm := newTestGame(t, opts)
+	gm.showMessage(message{Text: "hello\nworld"})
+
+	got := buf.String()
+	for _, want := range []string{`msg="show message"`, `text="hello\nworld"`, "duration=2s", "lines=2"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("log %q does not contain %q", got, want)
+		}
+	}
+}
+
+func TestDecodeGIFFrames(t *testing.T) {
+	red := color.RGBA{0xff, 0x00, 0x00, 0xff}
+	blue := color.RGBA{0x00, 0x00, 0xff, 0xff}
+	palette := color.Palette{color.Transparent, red, blue}
+	frame := func(r image.Rectangle, c uint8) *image.Paletted {
+		img := image.NewPaletted(r, palette)
+		for i := range img.Pix {
+			img.Pix[i] = c
+		}
+		return img
+	}
+	// 全体を赤で塗り、右下だけを青で上書きした後に消し、最後に左上だけを青で上書きする
+	g := &gif.GIF{
+		Image:    []*image.Paletted{frame(image.Rect(0, 0, 4, 4), 1), frame(image.Rect(2, 2, 4, 4), 2), frame(image.Rect(0, 0, 1, 1), 2)},
+		Delay:    []int{20, 0, 5},
+		Disposal: []byte{gif.DisposalNone, gif.DisposalBackground, gif.DisposalNone},
+		Config:   image.Config{Width: 4, Height: 4},
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+
+	frames, err := decodeGopherFrames(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 3 {
+		t.Fatalf("got %d frames, want 3", len(frames))
+	}
+	tests := []struct {
+		delay  float64
+		pixels map[image.Point]color.RGBA
+	}{
+		{0.2, map[image.Point]color.RGBA{{0, 0}: red, {3, 3}: red}},
+		{0.1, map[image.Point]color.RGBA{{0, 0}: red, {3, 3}: blue}},
+		{0.05, map[image.Point]color.RGBA{{0, 0}: blue, {1, 1}: red, {3, 3}: {}}},
+	}
+	for i, tt := range tests {
+		if frames[i].delay != tt.delay {
+			t.Errorf("frame %d: delay = %v, want %v", i, frames[i].delay, tt.delay)
+		}
+		img := readImage(frames[i].image)
+		for p, want := range tt.pixels {
+			if got := img.RGBAAt(p.X, p.Y); got != want {
+				t.Errorf("frame %d: pixel at %v = %v, want %v", i, p, got, want)
+			}
+		}
+	}
+}
+
+func TestUpdateCursorShape(t *testing.T) {
+	r := newTestGame(t, DefaultOptions()).gopherRect()
+	center := r.Min.Add(r.Size().Div(2))
+	tests := []struct {
+		name  string
+		setup func(gm *Game)
+		pos   image.Point
+		want  ebiten.CursorShapeType
+	}{
+		{"over the gopher", func(*Game) {}, center, ebiten.CursorShapeMove},
+		{"outside the gopher", func(*Game) {}, r.Min.Sub(image.Pt(1, 1)), ebiten.CursorShapeDefault},
+		{"dragging off the gopher", func(gm *Game) { gm.dragging = true }, image.Pt(-10, -10), ebiten.CursorShapeMove},
+		{"click-through", func(gm *Game) { gm.clickThrough = true }, center, ebiten.CursorShapeDefault},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm := newTestGame(t, DefaultOptions())
+			tt.setup(gm)
+			gm.updateCursorShape(tt.pos.X, tt.pos.Y)
+			if gm.cursorShape != tt.want {
+				t.Errorf("cursor shape = %v, want %v", gm.cursorShape, tt.want)
+			}
+		})
+	}
+}
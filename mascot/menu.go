@@ -0,0 +1,104 @@
+package mascot
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// メニューの描画パラメータ
+const (
+	menuPadX    = 12 // 項目の左右の余白
+	menuRowPadY = 10 // 項目の上下の余白の合計
+)
+
+// menuItem は右クリックメニューの項目。
+type menuItem int
+
+const (
+	menuNone menuItem = iota - 1
+	menuClear
+	menuQuit
+)
+
+// menuLabels は各項目の表示名。menuItem の値を添字とする。
+var menuLabels = []string{
+	menuClear: "Clear message",
+	menuQuit:  "Quit",
+}
+
+// menuHoverColor はカーソルが乗っている項目の背景色。
+var menuHoverColor = color.RGBA{R: 0xdd, G: 0xdd, B: 0xdd, A: 0xff}
+
+// updateMenu は右クリックメニューの開閉と項目の選択を処理する。Quit が選ばれた場合は true を返す。
+func (gm *Game) updateMenu(cx, cy int) bool {
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) && gm.hitGopher(cx, cy) {
+		gm.menuOpen = true
+		gm.menuX, gm.menuY = cx, cy
+		return false
+	}
+	if !gm.menuOpen || !inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		return false
+	}
+
+	// 項目の外をクリックした場合もメニューを閉じる
+	gm.menuOpen = false
+	gm.suppressDrag = true
+	switch gm.menuItemAt(cx, cy) {
+	case menuClear:
+		gm.clearMessage()
+	case menuQuit:
+		return true
+	}
+	return false
+}
+
+// menuRowH はメニューの1項目の高さを返す。
+func (gm *Game) menuRowH() int {
+	return gm.layoutCfg.FontSize + menuRowPadY
+}
+
+// menuRect はウィンドウ内に収めたメニューの矩形を返す。
+func (gm *Game) menuRect() image.Rectangle {
+	w := int(maxTextWidth(gm.fontFace, menuLabels)) + menuPadX*2
+	h := len(menuLabels) * gm.menuRowH()
+	x := max(min(gm.menuX, gm.screenWidth-w), 0)
+	y := max(min(gm.menuY, gm.screenHeight-h), 0)
+	return image.Rect(x, y, x+w, y+h)
+}
+
+// menuItemAt は座標にあるメニュー項目を返す。項目がなければ menuNone を返す。
+func (gm *Game) menuItemAt(x, y int) menuItem {
+	r := gm.menuRect()
+	if !image.Pt(x, y).In(r) {
+		return menuNone
+	}
+	return menuItem((y - r.Min.Y) / gm.menuRowH())
+}
+
+// drawMenu は右クリックメニューを描画する。
+func (gm *Game) drawMenu(screen *ebiten.Image) {
+	r := gm.menuRect()
+	s := float32(gm.deviceScale)
+	x, y := float32(r.Min.X)*s, float32(r.Min.Y)*s
+	w, h := float32(r.Dx())*s, float32(r.Dy())*s
+	rowH := float32(gm.menuRowH()) * s
+
+	vector.FillRect(screen, x, y, w, h, gm.bubbleFill, false)
+	cx, cy := gm.cursorPosition()
+	if hover := gm.menuItemAt(cx, cy); hover != menuNone {
+		vector.FillRect(screen, x, y+float32(hover)*rowH, w, rowH, menuHoverColor, false)
+	}
+	vector.StrokeRect(screen, x, y, w, h, s, gm.bubbleStroke, false)
+
+	for i, label := range menuLabels {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(float64(r.Min.X+menuPadX)*gm.deviceScale, float64(r.Min.Y+i*gm.menuRowH()+menuRowPadY/2)*gm.deviceScale)
+		op.ColorScale.ScaleWithColor(gm.bubbleStroke)
+		text.Draw(screen, label, gm.drawFace, op)
+	}
+}
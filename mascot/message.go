@@ -0,0 +1,51 @@
+package mascot
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// message は表示するメッセージと、その表示方法の指定。
+type message struct {
+	Text        string   `json:"text"`
+	DurationSec *float64 `json:"durationSec,omitempty"` // 表示秒数。未指定なら Game の表示時間設定に従い、0なら消えない
+	Style       string   `json:"style,omitempty"`       // 吹き出しのスタイル（"speech" または "think"）
+	Color       string   `json:"color,omitempty"`       // 文字色（"#rrggbb" または "#rrggbbaa"）。未指定・不正な値なら黒
+	Align       string   `json:"align,omitempty"`       // テキストの揃え方（"left"・"center"・"right"）。未指定なら Game の設定に従う
+	Image       string   `json:"image,omitempty"`       // 表示中に使うGopher画像の名前（Options.ImageDir のファイル名）。未指定・不明なら通常の画像
+	Sticky      bool     `json:"sticky,omitempty"`      // true なら時間切れで消さず、クリック・CLEAR・次のメッセージまで表示する
+	Progress    *float64 `json:"progress,omitempty"`    // 進捗（0〜1）。指定すると吹き出しに進捗バーを表示し、続く進捗付きのメッセージで置き換える
+}
+
+// parseMessage は入力された1行をメッセージに変換する。
+// {"text":"...","durationSec":5,"style":"think"} 形式のJSONであればその指定を使い、
+// それ以外は行全体をテキストとして扱う。text のないJSONは表示する文字のないメッセージになり、待ち行列で捨てられる。
+func parseMessage(line string) message {
+	if strings.HasPrefix(strings.TrimSpace(line), "{") {
+		var msg message
+		if err := json.Unmarshal([]byte(line), &msg); err == nil {
+			return msg
+		}
+	}
+	return message{Text: line}
+}
+
+// blank はメッセージに表示する文字がない（空白・改行・記法の記号だけ）かどうかを返す。
+// 文字のある行に挟まれた空行は段落の区切りとしてそのまま表示する。
+func (msg message) blank() bool {
+	plain, _ := parseMarkup(stripANSI(strings.ReplaceAll(msg.Text, "\\n", "\n")), nil)
+	return strings.TrimSpace(plain) == ""
+}
+
+// frames はメッセージの表示フレーム数を返す。0 は時間切れで消えないことを表す。
+func (msg message) frames(d DisplayDuration, text string) int {
+	if msg.Sticky {
+		return 0
+	}
+	if msg.DurationSec == nil {
+		return d.frames(text)
+	}
+	return int(max(*msg.DurationSec, 0) * float64(ebiten.TPS()))
+}
@@ -0,0 +1,91 @@
+package mascot
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want message
+	}{
+		{"plain", "hello", message{Text: "hello"}},
+		{"plain with braces", "a {b} c", message{Text: "a {b} c"}},
+		{"json", `{"text":"hi","style":"think"}`, message{Text: "hi", Style: "think"}},
+		{"json with spaces", `  {"text":"hi"}`, message{Text: "hi"}},
+		{"invalid json", `{"text":`, message{Text: `{"text":`}},
+		{"json array", `["hi"]`, message{Text: `["hi"]`}},
+		{"empty text", `{"text":""}`, message{}},
+		{"no text", `{"style":"think"}`, message{Style: "think"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseMessage(tt.line); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseMessage(%q) = %+v, want %+v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnqueueDropsJSONWithoutText(t *testing.T) {
+	gm := &Game{}
+	gm.enqueue(parseMessage(`{"style":"think"}`))
+	if n := len(gm.msgQueue); n != 0 {
+		t.Errorf("%d messages queued, want 0", n)
+	}
+}
+
+func TestEnqueueBlank(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		blank bool
+	}{
+		{"spaces", "     ", true},
+		{"tabs and spaces", "\t  \t", true},
+		{"newlines", "\n\n", true},
+		{"escaped newlines", `\n\n`, true},
+		{"code fence only", "```", true},
+		{"json spaces", `{"text":"   "}`, true},
+		{"text with spaces", "  a  ", false},
+		{"blank line between paragraphs", `a\n\nb`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm := &Game{}
+			gm.enqueue(parseMessage(tt.line))
+			want := 1
+			if tt.blank {
+				want = 0
+			}
+			if n := gm.queueLen(); n != want {
+				t.Errorf("queueLen() = %d, want %d", n, want)
+			}
+		})
+	}
+}
+
+func TestShowMessageKeepsWhitespace(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{"run of spaces", "a   b", []string{"a   b"}},
+		{"blank line between paragraphs", `a\n\nb`, []string{"a", "", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm := newTestGame(t, DefaultOptions())
+			gm.showMessage(parseMessage(tt.line))
+			if !gm.hasMessage {
+				t.Fatal("hasMessage = false, want true")
+			}
+			if got := gm.layout.lines; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
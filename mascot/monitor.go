@@ -0,0 +1,32 @@
+package mascot
+
+import (
+	"fmt"
+	"image"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// selectMonitor は index 番目のモニターを返す。0 が主モニター。
+// index が負の場合は nil を返し、範囲外の場合は主モニターを返す。
+func selectMonitor(index int) *ebiten.MonitorType {
+	if index < 0 {
+		return nil
+	}
+	monitors := ebiten.AppendMonitors(nil)
+	if len(monitors) == 0 {
+		return nil
+	}
+	if index >= len(monitors) {
+		fmt.Fprintf(os.Stderr, "monitor %d not found (%d monitors); using the primary monitor\n", index, len(monitors))
+		index = 0
+	}
+	return monitors[index]
+}
+
+// monitorBounds は現在のモニターの範囲を、ウィンドウ位置の座標で返す。
+// Ebiten のウィンドウ位置は現在のモニターの左上を原点とするため、範囲は常に (0, 0) から始まる。
+func monitorBounds() image.Rectangle {
+	return image.Rectangle{Max: image.Pt(ebiten.Monitor().Size())}
+}
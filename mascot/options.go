@@ -0,0 +1,307 @@
+package mascot
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Options はマスコットの設定。DefaultOptions の値をもとに必要な項目だけ変更して使う。
+type Options struct {
+	// Input から1行ずつメッセージを読み込む。nil の場合は読み込まない
+	Input io.Reader
+	// Sources の各入力元からもメッセージを受け取る。Input と合わせて、すべて終わると入力の終わりとみなす
+	Sources []InputSource
+
+	Corner         string          // ウィンドウを配置する画面の角（"bottom-right" など）
+	Placement      string          // 吹き出しを置く位置（"auto"・"above"・"below"）。auto は画面の上にはみ出す場合だけ下に置く
+	FixedSize      string          // ウィンドウの大きさを固定する（"幅x高さ"）。空なら内容に合わせてリサイズする
+	Monitor        int             // 表示するモニターの番号（0 が主モニター）。負なら起動時のモニター、範囲外なら主モニター
+	Font           string          // フォントファイル（TrueType・OpenType）のパス。空または読み込めない場合は埋め込みのフォントを使う
+	FallbackFonts  []string        // 主フォントにない文字の描画に順に使うフォントファイルのパス
+	BoldFont       string          // *太字* の文字に使う太字のフォントファイルのパス。空なら通常の書体をずらして重ね描きする
+	CJKFont        string          // 日本語・中国語・韓国語と判定したメッセージに、主フォントより優先して使うフォントファイルのパス
+	FontSize       int             // 文字サイズ(px)。8〜96 の範囲外の値は既定の 24 になる
+	LineSpacing    float64         // 複数行のメッセージの行間に追加する余白(px)
+	MaxWidth       int             // テキストを折り返す最大幅(px)。0 で既定の 350、100 未満は 100 になる
+	MaxHeight      int             // 吹き出し内のテキストの最大の高さ(px)。超える分はスクロールする。0 で上限なし
+	MaxLines       int             // 折り返し後に表示する最大の行数。超える分は省略記号を付けて切り詰める。0 で上限なし
+	TabWidth       int             // タブを展開する桁の間隔。0 で既定の 4
+	MaxLineBytes   int             // 入力から読み込む1行の最大バイト数。64KiB 未満は 64KiB になる
+	ClearSentinel  string          // 入力された行がこれと一致したら表示中のメッセージを消す。前にバックスラッシュを付けるとそのまま表示する。空なら消さない
+	StripANSI      bool            // メッセージから ANSI エスケープシーケンス（端末の色指定など）を取り除く
+	IdleSec        float64         // メッセージがない状態がこの秒数続いたら、一覧からランダムにひとことを話す。0 で話さない
+	IdleFile       string          // 待機中に話すひとことの一覧（1行に1つ）のパス。空または読み込めない場合は埋め込みの一覧を使う
+	Align          string          // テキストの揃え方（"left"・"center"・"right"）
+	Duration       DisplayDuration // メッセージの表示時間
+	Sticky         bool            // メッセージを時間切れで消さず、クリック・CLEAR・次のメッセージまで表示する
+	BubbleFill     color.Color     // 吹き出しの塗りつぶし色。nil の場合は白
+	BubbleStroke   color.Color     // 吹き出しの枠線の色。nil の場合は黒
+	OutlineWidth   float64         // 文字の縁取りの太さ(px)。0 で縁取りしない
+	OutlineColor   color.Color     // 文字の縁取りの色。nil の場合は白
+	BubbleRadius   float64         // 吹き出しの角丸の半径(px)。0 で角ばった吹き出しになる
+	BubblePadX     float64         // 吹き出しの左右の余白の合計(px)
+	BubblePadY     float64         // 吹き出しの上下の余白の合計(px)
+	StrokeWidth    float64         // 吹き出しの枠線の太さ(px)。既定より太い場合は、枠線の外側の縁が変わらないよう内側に寄せて描く
+	StrokeCap      string          // 吹き出しとしっぽの枠線の端の形（"butt"・"round"・"square"）。空なら吹き出しは butt、しっぽは round
+	StrokeJoin     string          // 吹き出しとしっぽの枠線の角の形（"miter"・"round"・"bevel"）。空なら吹き出しは miter、しっぽは round
+	BubbleGap      float64         // 吹き出しとGopherの間隔(px)。しっぽはこの間隔に描く
+	TailWidth      float64         // 吹き出しのしっぽの付け根の幅(px)。0 でしっぽを描かない
+	TailLength     float64         // 吹き出しのしっぽの付け根から先端までの高さ(px)。0 でしっぽを描かない
+	MarginSide     float64         // Gopherとウィンドウの左右の端との間隔(px)
+	MarginBottom   float64         // Gopherとウィンドウの下端との間隔(px)
+	ShadowOffset   float64         // 吹き出しの影を右下にずらす量(px)。0 で影を描かない
+	ShadowOpacity  float64         // 吹き出しの影の不透明度（0〜1）
+	AntiAlias      bool            // 吹き出し・しっぽ・影などの図形にアンチエイリアスをかける。false ならドット絵に合うくっきりした縁になる
+	Opacity        float64         // Gopherと吹き出しを含むウィンドウ全体の不透明度（0〜1）
+	BlurOpacity    float64         // ウィンドウがフォーカスを失っている間に Opacity に掛ける値（0〜1）。1 で薄くしない
+	BlurFadeSec    float64         // フォーカスの変化で薄くする・戻す際にかける秒数。0 で即座に切り替える
+	Background     color.Color     // ウィンドウの背景色。nil なら透明。透明なウィンドウに対応していない環境や、クロマキーで切り抜く場合に使う
+	RevealCPS      float64         // タイプライター表示の最小の速度（文字/秒）。0 で一度に表示する
+	RevealMaxCPS   float64         // タイプライター表示の最大の速度（文字/秒）。長いメッセージは表示時間の 30% で表示し終えるよう、この速度まで速める
+	FadeSec        float64         // 吹き出しのフェードにかける秒数。0 でフェードしない
+	ResizeSec      float64         // ウィンドウのリサイズをアニメーションさせる秒数。0 で即座にリサイズする
+	Sound          string          // 通知音（WAV・Ogg Vorbis）のパス。空または読み込めない場合は埋め込みの音を使う
+	Mute           bool            // 通知音を鳴らさない
+	TTS            bool            // メッセージをOSの音声合成コマンドで読み上げる
+	HistoryLen     int             // 現在のメッセージの上に、過去のメッセージを小さな吹き出しで表示する件数。0 で表示しない
+	HistoryFalloff float64         // 履歴の吹き出しが1件古くなるごとに掛ける不透明度（0〜1）
+	LookAtCursor   bool            // カーソルが近くにあるとき、Gopherを少しカーソルの方へ寄せて描画する
+	Tray           bool            // システムトレイにメニュー付きのアイコンを置く。-tags tray を付けてビルドした場合のみ（Windows・Linux）
+	ClickThrough   bool            // マウス操作を背後のウィンドウに通す
+	LowPower       bool            // 何も動いていない間は TPS を下げて CPU の使用を抑える
+	SnapPx         int             // Gopherのドラッグを終えた際に、ウィンドウをモニターの端に吸着させる距離(px)。0 で吸着しない
+	DoubleClickMs  int             // Gopherのダブルクリックとみなす2回のクリックの最大間隔(ms)。ダブルクリックで最前面表示を切り替える。0 で無効
+	ExitOnEOF      bool            // Input が終わり、最後のメッセージが消えたら終了する。HTTP・パイプ・ソケットを使う場合は終了しない
+	Logger         *slog.Logger    // 表示したメッセージを表示時間・行数とともに記録する。nil なら記録しない
+
+	HTTPAddr   string // メッセージを受け付ける HTTP サーバーのアドレス。空なら起動しない
+	PipePath   string // メッセージを読み込む名前付きパイプのパス。空なら読み込まない
+	SocketPath string // コマンドを受け付ける Unix ドメインソケットのパス。空なら受け付けない
+
+	RenderOut string // 空でなければ、ウィンドウを表示せず Message を表示した状態を PNG としてこのパスに書き出して Run を終了する
+	Message   string // RenderOut に書き出すメッセージ。JSON のメッセージも指定できる
+
+	Theme       string // テーマのディレクトリ。gopher.png・font.ttf・theme.json（色や余白）があれば、それぞれ個別の設定より優先して使う
+	Image       string // Gopher画像のパス（PNG・JPEG・GIF）。空または読み込めない場合は埋め込みの画像を使う
+	ImageDir    string // メッセージの "image" で名前を指定して切り替える画像（PNG・JPEG・GIF）を置いたディレクトリ
+	Size        int    // Gopher画像を収める正方形の一辺(px)。0 で既定の 300
+	Box         string // Gopher画像を縦横比を保って収める枠（"幅x高さ"）。空なら Size 四方に収める
+	Letterbox   bool   // 画像の縦横比に関わらず枠の大きさ分の場所を取り、画像を枠の下端・左右中央に置く
+	SpriteSheet string // 表情のスプライトシート画像のパス。空なら Gopher 画像を使う
+	SpriteSize  string // スプライトシートの1フレームのサイズ（"幅x高さ"）
+	Expressions string // 表情名とフレーム番号の対応（"neutral=0,talking=1" など）
+	Keywords    string // メッセージに含まれるキーワードと表情名の対応（"error=surprised,done=happy" など）。大文字・小文字は区別しない
+}
+
+// defaultDisplayDuration は指定がない場合の表示時間。
+var defaultDisplayDuration = DisplayDuration{
+	Base:    3,
+	PerChar: 0.2,
+	Max:     30,
+}
+
+// DefaultOptions は既定の設定を返す。
+func DefaultOptions() Options {
+	return Options{
+		Corner:         "bottom-right",
+		Placement:      "auto",
+		Monitor:        -1,
+		FontSize:       defaultFontSize,
+		LineSpacing:    lineSpacing,
+		MaxWidth:       defaultMaxLineWidth,
+		MaxHeight:      defaultMaxTextHeight,
+		MaxLineBytes:   defaultMaxLineBytes,
+		ClearSentinel:  defaultClearSentinel,
+		Align:          "left",
+		Duration:       defaultDisplayDuration,
+		BubbleFill:     color.White,
+		BubbleStroke:   color.Black,
+		OutlineColor:   color.White,
+		BubbleRadius:   bubbleRadius,
+		BubblePadX:     bubblePadX,
+		BubblePadY:     bubblePadY,
+		StrokeWidth:    strokeWidth,
+		BubbleGap:      bubbleGap,
+		TailWidth:      tailWidth,
+		TailLength:     tailLength,
+		MarginSide:     gopherMarginSide,
+		MarginBottom:   gopherMarginBottom,
+		ShadowOpacity:  defaultShadowOpacity,
+		AntiAlias:      true,
+		Opacity:        1,
+		BlurOpacity:    1,
+		BlurFadeSec:    defaultBlurFadeSec,
+		SnapPx:         defaultSnapPx,
+		DoubleClickMs:  defaultDoubleClickMs,
+		HistoryFalloff: defaultHistoryFalloff,
+		RevealCPS:      defaultRevealCPS,
+		RevealMaxCPS:   defaultRevealMaxCPS,
+		FadeSec:        defaultFadeSec,
+		Size:           maxGopherPx,
+	}
+}
+
+// OptionsFromEnv は GOPHER_* 環境変数から設定を読み込む。未指定・不正な値の項目は既定値のままにする。
+// Input には標準入力を設定する。
+func OptionsFromEnv() Options {
+	opts := DefaultOptions()
+	opts.Input = os.Stdin
+	if v := os.Getenv("GOPHER_CORNER"); v != "" {
+		opts.Corner = v
+	}
+	if v := os.Getenv("GOPHER_PLACEMENT"); v != "" {
+		opts.Placement = v
+	}
+	opts.Monitor = envInt("GOPHER_MONITOR", opts.Monitor)
+	opts.Font = os.Getenv("GOPHER_FONT")
+	opts.FallbackFonts = filepath.SplitList(os.Getenv("GOPHER_FONT_FALLBACK"))
+	opts.BoldFont = os.Getenv("GOPHER_BOLD_FONT")
+	opts.CJKFont = os.Getenv("GOPHER_CJK_FONT")
+	opts.FontSize = envInt("GOPHER_FONT_SIZE", opts.FontSize)
+	opts.LineSpacing = envFloat("GOPHER_LINE_SPACING", opts.LineSpacing)
+	opts.MaxWidth = envInt("GOPHER_MAX_WIDTH", opts.MaxWidth)
+	opts.MaxHeight = envInt("GOPHER_MAX_HEIGHT", opts.MaxHeight)
+	opts.MaxLines = envInt("GOPHER_MAX_LINES", opts.MaxLines)
+	opts.TabWidth = envInt("GOPHER_TAB_WIDTH", opts.TabWidth)
+	opts.MaxLineBytes = envInt("GOPHER_MAX_LINE_BYTES", opts.MaxLineBytes)
+	opts.StripANSI = envBool("GOPHER_STRIP_ANSI")
+	opts.IdleSec = envFloat("GOPHER_IDLE_SEC", opts.IdleSec)
+	opts.IdleFile = os.Getenv("GOPHER_IDLE_FILE")
+	if v := os.Getenv("GOPHER_TEXT_ALIGN"); v != "" {
+		opts.Align = v
+	}
+	opts.Duration.Base = envFloat("GOPHER_MSG_DURATION", opts.Duration.Base)
+	opts.Duration.PerChar = envFloat("GOPHER_MSG_DURATION_PER_CHAR", opts.Duration.PerChar)
+	opts.Duration.Max = envFloat("GOPHER_MSG_DURATION_MAX", opts.Duration.Max)
+	opts.Sticky = envBool("GOPHER_STICKY")
+	opts.BubbleFill = envColor("GOPHER_BUBBLE_FILL", opts.BubbleFill)
+	opts.BubbleStroke = envColor("GOPHER_BUBBLE_STROKE", opts.BubbleStroke)
+	opts.BubbleRadius = envFloat("GOPHER_BUBBLE_RADIUS", opts.BubbleRadius)
+	opts.BubblePadX = envFloat("GOPHER_BUBBLE_PAD_X", opts.BubblePadX)
+	opts.BubblePadY = envFloat("GOPHER_BUBBLE_PAD_Y", opts.BubblePadY)
+	opts.StrokeWidth = envFloat("GOPHER_STROKE_WIDTH", opts.StrokeWidth)
+	opts.StrokeCap = os.Getenv("GOPHER_STROKE_CAP")
+	opts.StrokeJoin = os.Getenv("GOPHER_STROKE_JOIN")
+	opts.BubbleGap = envFloat("GOPHER_BUBBLE_GAP", opts.BubbleGap)
+	opts.TailWidth = envFloat("GOPHER_TAIL_WIDTH", opts.TailWidth)
+	opts.TailLength = envFloat("GOPHER_TAIL_LENGTH", opts.TailLength)
+	opts.MarginSide = envFloat("GOPHER_MARGIN_SIDE", opts.MarginSide)
+	opts.MarginBottom = envFloat("GOPHER_MARGIN_BOTTOM", opts.MarginBottom)
+	opts.ShadowOffset = envFloat("GOPHER_SHADOW_OFFSET", opts.ShadowOffset)
+	opts.ShadowOpacity = envFloat("GOPHER_SHADOW_OPACITY", opts.ShadowOpacity)
+	opts.Opacity = envFloat("GOPHER_OPACITY", opts.Opacity)
+	opts.OutlineWidth = envFloat("GOPHER_OUTLINE_WIDTH", opts.OutlineWidth)
+	opts.OutlineColor = envColor("GOPHER_OUTLINE_COLOR", opts.OutlineColor)
+	opts.BlurOpacity = envFloat("GOPHER_BLUR_OPACITY", opts.BlurOpacity)
+	opts.BlurFadeSec = envFloat("GOPHER_BLUR_FADE_SEC", opts.BlurFadeSec)
+	if b, err := strconv.ParseBool(os.Getenv("GOPHER_ANTIALIAS")); err == nil {
+		opts.AntiAlias = b
+	}
+	opts.Background = envColor("GOPHER_BG", opts.Background)
+	opts.RevealCPS = envFloat("GOPHER_REVEAL_CPS", opts.RevealCPS)
+	opts.RevealMaxCPS = envFloat("GOPHER_REVEAL_MAX_CPS", opts.RevealMaxCPS)
+	opts.FadeSec = envFloat("GOPHER_FADE_SEC", opts.FadeSec)
+	opts.ResizeSec = envFloat("GOPHER_RESIZE_SEC", opts.ResizeSec)
+	opts.Sound = os.Getenv("GOPHER_SOUND")
+	opts.Mute = envBool("GOPHER_MUTE")
+	opts.TTS = envBool("GOPHER_TTS")
+	opts.HistoryLen = envInt("GOPHER_HISTORY", opts.HistoryLen)
+	opts.HistoryFalloff = envFloat("GOPHER_HISTORY_FALLOFF", opts.HistoryFalloff)
+	opts.ClickThrough = envBool("GOPHER_CLICK_THROUGH")
+	opts.LowPower = envBool("GOPHER_LOW_POWER")
+	opts.SnapPx = envInt("GOPHER_SNAP", opts.SnapPx)
+	opts.DoubleClickMs = envInt("GOPHER_DOUBLE_CLICK_MS", opts.DoubleClickMs)
+	opts.ExitOnEOF = envBool("GOPHER_EXIT_ON_EOF")
+	if envBool("GOPHER_LOG") {
+		opts.Logger = slog.New(slog.NewTextHandler(os.Stderr, nil))
+	}
+	opts.HTTPAddr = os.Getenv("GOPHER_HTTP_ADDR")
+	opts.PipePath = os.Getenv("GOPHER_PIPE")
+	opts.SocketPath = os.Getenv("GOPHER_SOCK")
+	opts.RenderOut = os.Getenv("GOPHER_RENDER_OUT")
+	opts.Message = os.Getenv("GOPHER_MESSAGE")
+	opts.Image = os.Getenv("GOPHER_IMAGE")
+	opts.ImageDir = os.Getenv("GOPHER_IMAGE_DIR")
+	opts.Size = envInt("GOPHER_SIZE", opts.Size)
+	if v := os.Getenv("GOPHER_CLEAR_SENTINEL"); v != "" {
+		opts.ClearSentinel = v
+	}
+	opts.FixedSize = os.Getenv("GOPHER_FIXED_SIZE")
+	opts.LookAtCursor = envBool("GOPHER_LOOK_AT_CURSOR")
+	opts.Tray = envBool("GOPHER_TRAY")
+	opts.Theme = os.Getenv("GOPHER_THEME")
+	opts.Box = os.Getenv("GOPHER_BOX")
+	opts.Letterbox = envBool("GOPHER_LETTERBOX")
+	opts.SpriteSheet = os.Getenv("GOPHER_SPRITE_SHEET")
+	opts.SpriteSize = os.Getenv("GOPHER_SPRITE_SIZE")
+	opts.Expressions = os.Getenv("GOPHER_EXPRESSIONS")
+	opts.Keywords = os.Getenv("GOPHER_KEYWORDS")
+	return opts
+}
+
+// envFloat は環境変数を0以上の数値として読み込む。未指定・不正な値の場合は def を返す。
+func envFloat(key string, def float64) float64 {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 {
+		return def
+	}
+	return f
+}
+
+// envInt は環境変数を整数として読み込む。未指定・不正な値の場合は def を返す。
+func envInt(key string, def int) int {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// envBool は環境変数を真偽値として読み込む。"1" や "true" などは true、未指定・不正な値は false を返す。
+func envBool(key string) bool {
+	b, err := strconv.ParseBool(os.Getenv(key))
+	return err == nil && b
+}
+
+// envColor は環境変数を "#rrggbb" 形式の色として読み込む。未指定・不正な値の場合は def を返す。
+func envColor(key string, def color.Color) color.Color {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	c, err := parseHexColor(v)
+	if err != nil {
+		return def
+	}
+	return c
+}
+
+// parseHexColor は "#rrggbb" または "#rrggbbaa" 形式の文字列を色に変換する。先頭の # は省略できる。
+func parseHexColor(s string) (color.Color, error) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) != 6 && len(s) != 8 {
+		return nil, fmt.Errorf("invalid color %q", s)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("parse color %q: %w", s, err)
+	}
+	if len(s) == 6 {
+		v = v<<8 | 0xff
+	}
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
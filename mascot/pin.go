@@ -0,0 +1,68 @@
+package mascot
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	defaultDoubleClickMs = 400 // ダブルクリックとみなす既定の間隔(ms)
+	pinNoticeSec         = 1.5 // 最前面表示を切り替えた際の通知の表示秒数
+)
+
+// handleGopherClick はGopherのクリックを記録し、doubleClick 以内に続けてクリックされたら最前面表示を切り替える。
+// クリックはドラッグの開始も兼ねるため、ドラッグの処理には影響しない。
+func (gm *Game) handleGopherClick() {
+	if gm.doubleClick <= 0 {
+		return
+	}
+	now := time.Now()
+	if now.Sub(gm.lastClick) > gm.doubleClick {
+		gm.lastClick = now
+		return
+	}
+	// 続けて3回クリックした際に、2回目と3回目もダブルクリックとみなさないよう記録を消す
+	gm.lastClick = time.Time{}
+	gm.toggleFloating()
+}
+
+// toggleFloating はウィンドウを常に最前面に表示するかを切り替え、吹き出しで短く知らせる。
+func (gm *Game) toggleFloating() {
+	gm.floating = !gm.floating
+	ebiten.SetWindowFloating(gm.floating)
+	if gm.onToggle != nil {
+		gm.onToggle()
+	}
+	text := "Unpinned"
+	if gm.floating {
+		text = "Pinned on top"
+	}
+	sec := pinNoticeSec
+	gm.showNotice(message{Text: text, DurationSec: &sec})
+}
+
+// showNotice は待ち行列を待たずに msg をすぐに表示する。表示中のメッセージは残りの表示時間ごと取っておき、
+// 通知が消えたら表示し直す。通知は履歴や最近のメッセージに残さず、読み上げない。
+func (gm *Game) showNotice(msg message) {
+	if gm.browseIndex >= 0 {
+		gm.stopBrowsing()
+	}
+	// 通知の表示中に次の通知が来た場合は、最初に取っておいたメッセージをそのまま戻す
+	if !gm.noticing {
+		gm.noticeLive = gm.hasMessage && len(gm.recent) > 0
+		gm.noticeTimer = gm.msgTimer
+	}
+	gm.noticing = true
+	gm.showRecent(msg)
+	// すべてのメッセージを消さない設定でも、通知は表示時間が過ぎたら消して元のメッセージに戻す
+	gm.msgTimer = msg.frames(gm.duration, gm.messageText)
+}
+
+// resumeFromNotice は通知を消し、通知の前に表示していたメッセージを残りの表示時間ごと表示し直す。
+func (gm *Game) resumeFromNotice() {
+	gm.showRecent(gm.recent[0])
+	gm.msgTimer = gm.noticeTimer
+	gm.noticing = false
+	gm.noticeLive = false
+}
@@ -0,0 +1,54 @@
+package mascot
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestPinNoticePreemptsMessage(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Duration = DisplayDuration{Base: 10}
+	opts.HistoryLen = 3
+	gm := newTestGame(t, opts)
+	gm.Say("Hello, Gopher!")
+	gm.Say("next")
+	updateFrames(t, gm, 1)
+	timer := gm.msgTimer
+
+	// 通知は待ち行列を待たずに、表示中のメッセージを置き換えてすぐに表示する。
+	// ウィンドウは最前面表示で始まるため、切り替えると "Unpinned" を知らせる
+	gm.toggleFloating()
+	t.Cleanup(func() { ebiten.SetWindowFloating(true) })
+	if gm.messageText != "Unpinned" {
+		t.Fatalf("messageText after unpinning = %q, want %q", gm.messageText, "Unpinned")
+	}
+	if n := gm.queueLen(); n != 1 {
+		t.Errorf("queueLen() = %d, want 1", n)
+	}
+
+	// 通知が消えたら、元のメッセージを残りの表示時間ごと表示し直す
+	updateFrames(t, gm, int(pinNoticeSec*float64(ebiten.TPS()))+1)
+	if gm.messageText != "Hello, Gopher!" {
+		t.Fatalf("messageText after the notice = %q, want %q", gm.messageText, "Hello, Gopher!")
+	}
+	if gm.msgTimer > timer || gm.msgTimer < timer-2 {
+		t.Errorf("msgTimer = %d, want about %d", gm.msgTimer, timer)
+	}
+	if len(gm.history) != 0 || len(gm.recent) != 1 {
+		t.Errorf("notice left %d history entries and %d recent messages, want 0 and 1", len(gm.history), len(gm.recent))
+	}
+}
+
+func TestPinNoticeWithoutMessage(t *testing.T) {
+	gm := newTestGame(t, DefaultOptions())
+	gm.toggleFloating()
+	t.Cleanup(func() { ebiten.SetWindowFloating(true) })
+	if !gm.hasMessage || gm.messageText != "Unpinned" {
+		t.Fatalf("messageText = %q, want %q", gm.messageText, "Unpinned")
+	}
+	updateFrames(t, gm, int(pinNoticeSec*float64(ebiten.TPS()))+1)
+	if gm.hasMessage || gm.noticing {
+		t.Errorf("notice still shown: hasMessage = %v, noticing = %v", gm.hasMessage, gm.noticing)
+	}
+}
@@ -0,0 +1,94 @@
+package mascot
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+// pipeCloseTimeout は Close で、パイプを読み込むgoroutineが終わるのを待つ最長の時間。
+const pipeCloseTimeout = time.Second
+
+// startPipeReader は path が指定されていれば名前付きパイプ(FIFO)からメッセージを読み込む。
+// パイプが存在しなければ作成する。書き込み側が閉じても再度開き直して次の書き込みを待つため、
+// 複数のプロセスから順に書き込める。標準入力からの読み込みとは独立に動作する。
+// Close では、書き込み側を待っている Open と読み込み中の Read を解き、goroutineが終わるまで待つ。
+func (gm *Game) startPipeReader(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	fi, err := os.Stat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if err := mkfifo(path); err != nil {
+			return fmt.Errorf("create pipe %s: %w", path, err)
+		}
+	case err != nil:
+		return fmt.Errorf("stat pipe %s: %w", path, err)
+	case fi.Mode()&fs.ModeNamedPipe == 0:
+		return fmt.Errorf("%s is not a named pipe", path)
+	}
+
+	var (
+		mu   sync.Mutex
+		cur  *os.File // 読み込み中のパイプ
+		done = make(chan struct{})
+	)
+	gm.closers = append(gm.closers, func() error {
+		mu.Lock()
+		if cur != nil {
+			cur.Close()
+		}
+		mu.Unlock()
+		// Open で書き込み側を待っている間は、こちらから書き込み側として開いて待ちを解く。
+		// goroutineが Open に入る直前の場合もあるため、終わるまで繰り返す
+		timeout := time.After(pipeCloseTimeout)
+		for {
+			unblockPipeOpen(path)
+			select {
+			case <-done:
+				return nil
+			case <-timeout:
+				return fmt.Errorf("close pipe %s: reader did not stop", path)
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+	})
+
+	go func() {
+		defer close(done)
+		for gm.ctx.Err() == nil {
+			// 書き込み側が開くまでブロックする
+			f, err := os.Open(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "open pipe %s: %v\n", path, err)
+				return
+			}
+			mu.Lock()
+			if gm.ctx.Err() != nil {
+				mu.Unlock()
+				f.Close()
+				return
+			}
+			cur = f
+			mu.Unlock()
+
+			// 改行で終わらないまま書き込み側が閉じた場合、その残りも1行として扱う。
+			// 読み込みが終わってから閉じるため、readSource と違い Close の後もチャネルが閉じるまで受け取る
+			for line := range (ReaderSource{R: f, MaxLineBytes: gm.maxLineBytes}).Messages() {
+				if gm.ctx.Err() == nil {
+					gm.readLine(line)
+				}
+			}
+			mu.Lock()
+			cur = nil
+			mu.Unlock()
+			f.Close()
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package mascot
+
+import "errors"
+
+// mkfifo は名前付きパイプに対応していないプラットフォームではエラーを返す。
+func mkfifo(string) error {
+	return errors.New("named pipes are not supported on this platform")
+}
+
+// unblockPipeOpen は名前付きパイプに対応していないプラットフォームでは何もしない。
+func unblockPipeOpen(string) {}
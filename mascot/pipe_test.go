@@ -0,0 +1,63 @@
+//go:build unix
+
+package mascot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// waitQueue は待ち行列に n 件のメッセージが積まれるまで待つ。
+func waitQueue(t *testing.T, gm *Game, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for gm.queueLen() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("queueLen() = %d, want %d", gm.queueLen(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPipeReaderClose(t *testing.T) {
+	tests := []struct {
+		name   string
+		writer bool // 書き込み側を開いたまま Close する
+	}{
+		{"waiting for a writer", false},
+		{"reading from a writer", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.PipePath = filepath.Join(t.TempDir(), "gopher.fifo")
+			gm := newTestGame(t, opts)
+			if tt.writer {
+				w, err := os.OpenFile(opts.PipePath, os.O_WRONLY, 0)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer w.Close()
+				if _, err := w.WriteString("hello\n"); err != nil {
+					t.Fatal(err)
+				}
+				waitQueue(t, gm, 1)
+			}
+			// Close は読み込むgoroutineが終わるまで待ち、終わらなければエラーを返す
+			if err := gm.Close(); err != nil {
+				t.Fatalf("Close() = %v", err)
+			}
+			// 読み込み側がいなければ、書き込み側をブロックせずに開くと ENXIO で失敗する
+			if f, err := os.OpenFile(opts.PipePath, os.O_WRONLY|syscall.O_NONBLOCK, 0); err == nil {
+				f.Close()
+				t.Error("the pipe still has a reader after Close")
+			} else if !errors.Is(err, syscall.ENXIO) {
+				t.Errorf("open the pipe after Close: %v, want ENXIO", err)
+			}
+		})
+	}
+}
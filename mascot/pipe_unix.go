@@ -0,0 +1,23 @@
+//go:build unix
+
+package mascot
+
+import (
+	"os"
+	"syscall"
+)
+
+// mkfifo は名前付きパイプを作成する。
+func mkfifo(path string) error {
+	return syscall.Mkfifo(path, 0o600)
+}
+
+// unblockPipeOpen は、path を読み込み用に開こうとして書き込み側を待っている Open があれば、
+// 書き込み側として開いてすぐ閉じ、待ちを解く。待っている読み込み側がなければ何もしない。
+func unblockPipeOpen(path string) {
+	f, err := os.OpenFile(path, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return
+	}
+	f.Close()
+}
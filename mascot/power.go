@@ -0,0 +1,95 @@
+package mascot
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// lowPowerTPS は省電力モードで、何も動いていない間に下げる TPS。
+const lowPowerTPS = 10
+
+// animating は滑らかに更新する必要がある状態（メッセージの表示・ドラッグ・アニメーション・マウス操作の最中）かどうかを返す。
+func (gm *Game) animating() bool {
+	return gm.hasMessage || gm.queueLen() > 0 ||
+		gm.dragging || gm.bubbleDragging || gm.menuOpen ||
+		gm.resize != nil || gm.bubbleAlpha > 0 || gm.bounceTimer > 0 || gm.blinkFrame > 0 ||
+		len(gm.history) > 0 || len(gm.gopherFrames) > 1 || gm.progressAnimating() ||
+		ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) || ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight)
+}
+
+// updatePower は省電力モードで、何も動いていない間は TPS を lowPowerTPS に下げ、動き出したら既定の TPS に戻す。
+// 表示時間やアニメーションの長さは、始めた時点の ebiten.TPS() でフレーム数に換算して数える。
+// 下げた TPS のまま換算すると既定の TPS に戻した後で短くなるため、Update ではメッセージを取り出す前に呼ぶ。
+func (gm *Game) updatePower() {
+	if !gm.lowPower {
+		return
+	}
+	tps := lowPowerTPS
+	if gm.animating() {
+		tps = ebiten.DefaultTPS
+	}
+	if ebiten.TPS() != tps {
+		ebiten.SetTPS(tps)
+	}
+}
+
+// drawState は描画の結果を左右する状態のうち、フレームごとに少しずつ変わるもの。
+// メッセージやレイアウトの切り替えは relayout などで直接 dirty にする。
+type drawState struct {
+	revealedChars      int
+	bubbleAlpha        float64
+	focusDim           float64
+	opacity            float64
+	bounce             float64
+	blinkFrame         int
+	frameIndex         int
+	leanX, leanY       float64
+	scrollY            float64
+	progress           float64
+	dragging           bool
+	bubbleX, bubbleY   float32
+	tailTipX, tailTipY float32
+}
+
+// currentDrawState は現在の描画に関わる状態を返す。
+func (gm *Game) currentDrawState() drawState {
+	return drawState{
+		revealedChars: gm.revealedChars,
+		bubbleAlpha:   gm.bubbleAlpha,
+		focusDim:      gm.focusDim,
+		opacity:       gm.opacity,
+		bounce:        gm.bounceOffset(),
+		blinkFrame:    gm.blinkFrame,
+		frameIndex:    gm.frameIndex,
+		leanX:         gm.leanX,
+		leanY:         gm.leanY,
+		scrollY:       gm.scrollY,
+		progress:      gm.progress,
+		dragging:      gm.dragging,
+		bubbleX:       gm.layout.bubbleX,
+		bubbleY:       gm.layout.bubbleY,
+		tailTipX:      gm.layout.tailTipX,
+		tailTipY:      gm.layout.tailTipY,
+	}
+}
+
+// updateRedraw は次の Draw で描画し直す必要があるかを判定する。描画に関わる状態が前のフレームから変わった場合と、
+// サイズ変更のアニメーション中・履歴のフェードアウト中・メニューを開いている間（カーソルの位置で項目を強調する）は描画し直す。
+// 止まっている間は、全文を表示し終えた吹き出しを出したままでも前のフレームの画面をそのまま使い、描画を省く
+// （Run で画面を毎フレーム消さない設定にしている）。
+func (gm *Game) updateRedraw() {
+	s := gm.currentDrawState()
+	if s != gm.drawn || gm.resize != nil || gm.historyFading() || gm.menuOpen {
+		gm.dirty = true
+	}
+	gm.drawn = s
+}
+
+// needsRedraw は Draw で画面を描画し直す必要があるかを返す。
+// ウィンドウのサイズが変わった場合は、前のフレームの画面が残らないため必ず描画し直す。
+func (gm *Game) needsRedraw(screen *ebiten.Image) bool {
+	size := screen.Bounds().Size()
+	if !gm.dirty && size == gm.drawnSize {
+		return false
+	}
+	gm.dirty = false
+	gm.drawnSize = size
+	return true
+}
@@ -0,0 +1,67 @@
+package mascot
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestLowPowerMessageUsesDefaultTPS(t *testing.T) {
+	opts := DefaultOptions()
+	opts.LowPower = true
+	opts.Duration = DisplayDuration{Base: 2}
+	gm := newTestGame(t, opts)
+	t.Cleanup(func() { ebiten.SetTPS(ebiten.DefaultTPS) })
+
+	// 何も動いていない間は TPS が下がる
+	updateFrames(t, gm, 2)
+	if tps := ebiten.TPS(); tps != lowPowerTPS {
+		t.Fatalf("idle TPS = %d, want %d", tps, lowPowerTPS)
+	}
+
+	// 下がっている間に届いたメッセージも、既定の TPS で表示時間を数える
+	gm.Say("hello")
+	updateFrames(t, gm, 1)
+	if tps := ebiten.TPS(); tps != ebiten.DefaultTPS {
+		t.Fatalf("TPS while showing a message = %d, want %d", tps, ebiten.DefaultTPS)
+	}
+	if want := gm.duration.frames("hello"); gm.msgTimer < want-1 {
+		t.Errorf("msgTimer = %d, want about %d frames at %d TPS", gm.msgTimer, want, ebiten.DefaultTPS)
+	}
+}
+
+func TestStaticBubbleSkipsRedraw(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Duration = DisplayDuration{} // 時間切れで消さない
+	gm := newTestGame(t, opts)
+	gm.Say("Hello, Gopher!")
+	// 全文を表示し終え、フェードイン・跳ねるアニメーションが終わるまで進める
+	updateFrames(t, gm, 3*ebiten.DefaultTPS)
+	if !gm.hasMessage || gm.revealedChars < gm.layout.charCount() {
+		t.Fatalf("message not fully shown: hasMessage = %v, revealed %d of %d", gm.hasMessage, gm.revealedChars, gm.layout.charCount())
+	}
+
+	tests := []struct {
+		name   string
+		change func()
+		want   bool
+	}{
+		{"static bubble", func() {}, false},
+		{"bounce", func() { gm.bounceTimer = bounceFrames() }, true},
+		{"fade", func() { gm.bubbleAlpha = 0.5 }, true},
+		{"reveal", func() { gm.revealedChars = 0 }, true},
+		// 消える直前の履歴は、ほかの状態が変わらなくても不透明度が毎フレーム下がる
+		{"history fade", func() { gm.history = []historyEntry{{text: "old", timer: 10}} }, true},
+	}
+	for _, tt := range tests {
+		updateFrames(t, gm, ebiten.DefaultTPS) // 前の変更が落ち着くまで進める
+		gm.blinkTimer = 1 << 20                // 途中でまばたきしないようにする
+		gm.dirty = false
+		tt.change()
+		// 跳ね始めのフレームは位置が変わらないため、2フレーム進めて確かめる
+		updateFrames(t, gm, 2)
+		if gm.dirty != tt.want {
+			t.Errorf("%s: dirty = %v, want %v", tt.name, gm.dirty, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package mascot
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	progressBarH   = 8     // 進捗バーの高さ(px)
+	progressGap    = 6     // テキストと進捗バーの間隔(px)
+	progressMinW   = 160   // 進捗バーの最小の幅(px)。テキストが短くても吹き出しをこの幅まで広げる
+	progressEase   = 0.2   // 進捗バーの表示を1フレームで目標の値に近づける割合
+	progressSnapAt = 0.001 // 進捗バーの表示と目標の値の差がこれより小さくなったら目標の値に揃える
+)
+
+var (
+	progressTrackColor = color.RGBA{0xdd, 0xdd, 0xdd, 0xff} // 進捗バーの未完了の部分
+	progressFillColor  = color.RGBA{0x00, 0xad, 0xd8, 0xff} // 進捗バーの完了した部分（Go のブランドカラー）
+)
+
+// progressWidth は進捗 p（0〜1）の場合に、幅 w のバーのうち塗る幅を返す。範囲外の p は 0〜1 に収める。
+func progressWidth(p float64, w float32) float32 {
+	return w * float32(min(max(p, 0), 1))
+}
+
+// updateProgress は進捗バーの表示を目標の値に少しずつ近づけ、更新の間を滑らかにつなぐ。
+func (gm *Game) updateProgress() {
+	if !gm.hasProgress {
+		return
+	}
+	d := gm.progressTarget - gm.progress
+	if math.Abs(d) < progressSnapAt {
+		gm.progress = gm.progressTarget
+		return
+	}
+	gm.progress += d * progressEase
+}
+
+// progressAnimating は進捗バーが目標の値に向かって動いている途中かどうかを返す。
+func (gm *Game) progressAnimating() bool {
+	return gm.hasProgress && gm.progress != gm.progressTarget
+}
+
+// drawProgress は吹き出しの下端の余白の上に進捗バーを描画する。
+func (gm *Game) drawProgress(screen *ebiten.Image, ly layout) {
+	if !gm.hasProgress || ly.progressH == 0 {
+		return
+	}
+	s := float32(gm.deviceScale)
+	cfg := gm.layoutCfg
+	x := ly.bubbleX + float32(cfg.BubblePadX)/2
+	y := ly.bubbleY + ly.bubbleH - float32(cfg.BubblePadY)/2 - progressBarH
+	w := ly.bubbleW - float32(cfg.BubblePadX)
+	vector.FillRect(screen, x*s, y*s, w*s, progressBarH*s, progressTrackColor, gm.antiAlias)
+	vector.FillRect(screen, x*s, y*s, progressWidth(gm.progress, w)*s, progressBarH*s, progressFillColor, gm.antiAlias)
+}
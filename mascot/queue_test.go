@@ -0,0 +1,72 @@
+package mascot
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQueueShowsMessagesInTurn(t *testing.T) {
+	gm := newTestGame(t, DefaultOptions())
+	gm.duration = DisplayDuration{Base: 0.5}
+	var want []string
+	for i := range 5 {
+		want = append(want, fmt.Sprintf("line %d", i))
+		gm.enqueue(message{Text: want[i]})
+	}
+
+	// 表示されたメッセージを順に記録し、それぞれが表示時間いっぱい表示されたことを確かめる。
+	// 表示時間は、表示を始めたフレームを含めた Update の回数で数える
+	var shown []string
+	frames := gm.duration.frames("line 0")
+	shownAt := 0
+	for frame := range 10 * frames {
+		updateFrames(t, gm, 1)
+		if !gm.hasMessage {
+			continue
+		}
+		text := strings.Join(gm.layout.lines, "\n")
+		if n := len(shown); n == 0 || shown[n-1] != text {
+			if n > 0 && frame-shownAt+1 < frames {
+				t.Errorf("%q replaced %q after %d frames, want %d", text, shown[n-1], frame-shownAt+1, frames)
+			}
+			shown = append(shown, text)
+			shownAt = frame
+		}
+	}
+	if !reflect.DeepEqual(shown, want) {
+		t.Errorf("shown messages = %q, want %q", shown, want)
+	}
+	if gm.hasMessage || len(gm.msgQueue) > 0 {
+		t.Errorf("hasMessage = %v, %d queued after all messages expired", gm.hasMessage, len(gm.msgQueue))
+	}
+}
+
+func TestQueueSwitchesWithoutShrinking(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Duration = DisplayDuration{Base: 0.2}
+	gm := newTestGame(t, opts)
+	gm.Say("one\ntwo")
+	gm.Say("one\ntwo\nthree\nfour")
+
+	// ウィンドウの大きさが変わるたびに記録する。2件目に切り替わる際に、メッセージなしの大きさを挟まない
+	type size struct{ w, h int }
+	want := []size{
+		{gm.screenWidth, gm.screenHeight + 28},   // 2行のメッセージ
+		{gm.screenWidth, gm.screenHeight + 28*3}, // 4行のメッセージ
+		{gm.screenWidth, gm.screenHeight},        // メッセージなし
+	}
+	var got []size
+	last := size{gm.screenWidth, gm.screenHeight}
+	for range 10 * gm.duration.frames("") {
+		updateFrames(t, gm, 1)
+		if s := (size{gm.screenWidth, gm.screenHeight}); s != last {
+			got = append(got, s)
+			last = s
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("window sizes = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+package mascot
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// renderGame はメッセージを表示した状態の Gopher と吹き出しを PNG に書き出して終了する ebiten.Game。
+// Ebiten の描画にはグラフィックスの初期化が必要なため、何も描かない透明なウィンドウを開き、最初のフレームで書き出す。
+type renderGame struct {
+	gm   *Game
+	msg  message
+	path string
+	err  error
+}
+
+func (rg *renderGame) Update() error {
+	rg.err = rg.gm.renderPNG(rg.msg, rg.path)
+	return ebiten.Termination
+}
+
+func (rg *renderGame) Draw(*ebiten.Image) {}
+
+func (rg *renderGame) Layout(_, _ int) (int, int) {
+	return 1, 1
+}
+
+// runRender はメッセージを PNG に書き出して終了する。Run の代わりに使う。
+func (gm *Game) runRender() error {
+	ebiten.SetWindowSize(1, 1)
+	ebiten.SetWindowDecorated(false)
+	rg := &renderGame{gm: gm, msg: parseMessage(gm.renderMessage), path: gm.renderOut}
+	if err := ebiten.RunGameWithOptions(rg, &ebiten.RunGameOptions{
+		InitUnfocused:     true,
+		ScreenTransparent: true,
+		SkipTaskbar:       true,
+	}); err != nil {
+		return err
+	}
+	return rg.err
+}
+
+// renderPNG はメッセージを全文表示した状態を描画し、ウィンドウと同じ大きさの PNG として path に書き出す。
+// アニメーション・通知音・読み上げは行わず、ウィンドウの大きさと位置も変えない。
+func (gm *Game) renderPNG(msg message, path string) error {
+	if err := gm.updateDeviceScale(); err != nil {
+		return err
+	}
+	// showMessage はウィンドウをリサイズするため使わず、レイアウトだけを計算する
+	wrapped := gm.setMessage(msg)
+	gm.hasProgress = msg.Progress != nil
+	if gm.hasProgress {
+		gm.progressTarget = min(max(*msg.Progress, 0), 1)
+		gm.progress = gm.progressTarget
+	}
+	textW := gm.textWidth(strings.Split(wrapped, "\n"))
+	// ウィンドウの位置に左右されないよう、吹き出しの上下は配置の設定だけで決める
+	below := gm.placement == placementBelow
+	ly, sw, sh := calcLayout(gm.gopherImage.Bounds().Size(), textW, wrapped, gm.hasProgress, nil, gm.corner, below, gm.layoutCfg)
+	ly.bubbleStyle = parseBubbleStyle(msg.Style)
+	ly.setBubbleOffset(gm.bubbleOffX, gm.bubbleOffY, sw, sh)
+	gm.layout = ly
+	gm.screenWidth = sw
+	gm.screenHeight = sh
+	gm.hasMessage = true
+	gm.revealedChars = ly.charCount()
+	gm.bubbleAlpha = 1
+	gm.bounceTimer = 0
+	gm.setExpression(gm.messageExpression(gm.messageText))
+
+	w, h := gm.physicalSize(gm.screenWidth, gm.screenHeight)
+	img := ebiten.NewImage(w, h)
+	defer img.Deallocate()
+	gm.clearScreen(img)
+	gm.drawContent(img)
+	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+	img.ReadPixels(rgba.Pix)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create png: %w", err)
+	}
+	if err := png.Encode(f, rgba); err != nil {
+		f.Close()
+		return fmt.Errorf("encode png: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close png: %w", err)
+	}
+	return nil
+}
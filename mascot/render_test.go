@@ -0,0 +1,537 @@
+package mascot
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+var update = flag.Bool("update", false, "update the golden images in testdata/golden")
+
+const (
+	goldenTolerance = 16    // 画素の各チャネルで許す差。GPU によるアンチエイリアスの違いを吸収する
+	goldenMaxDiff   = 0.005 // 許す差を超えた画素の割合の上限
+)
+
+func TestDrawBubbleGolden(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		below    bool // 吹き出しをGopherの下に置く
+		opts     func(*Options)
+		tailOnly bool // しっぽの周りだけを比べる
+	}{
+		{name: "empty"},
+		{name: "single_line", message: "Hello, Gopher!"},
+		{name: "multi_line", message: "Hello, Gopher!\nこんにちは\n*bold* and _italic_"},
+		{name: "tail", message: "Hello, Gopher!", tailOnly: true},
+		{name: "tail_below", message: "Hello, Gopher!", below: true, tailOnly: true},
+		{name: "think_tail", message: `{"text":"Hmm...","style":"think"}`, tailOnly: true},
+		{name: "square", message: "Hello, Gopher!", opts: func(o *Options) { o.BubbleRadius = 0 }},
+		{name: "thick_border", message: "Hello, Gopher!", opts: func(o *Options) {
+			o.BubblePadX, o.BubblePadY, o.StrokeWidth = 80, 60, 6
+		}},
+		// 白い吹き出しの上でも見えるよう、縁取りに色を付ける
+		{name: "outline", message: "Hello, Gopher!", opts: func(o *Options) {
+			o.OutlineWidth, o.OutlineColor = 2, color.RGBA{0x00, 0xad, 0xd8, 0xff}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			if tt.opts != nil {
+				tt.opts(&opts)
+			}
+			gm := newTestGame(t, opts)
+			// 自動の配置はウィンドウの位置で上下が変わるため、どちらかに決めて描画する
+			gm.placement = placementAbove
+			if tt.below {
+				gm.placement = placementBelow
+			}
+			if tt.message != "" {
+				gm.showMessage(parseMessage(tt.message))
+			}
+			gm.revealedChars = gm.layout.charCount()
+
+			img := ebiten.NewImage(gm.screenWidth, gm.screenHeight)
+			defer img.Deallocate()
+			gm.drawBubble(img, gm.layout)
+			gm.drawText(img, gm.layout)
+			got := readImage(img)
+			if tt.tailOnly {
+				got = got.SubImage(tailRect(gm.layout, gm.layoutCfg)).(*image.RGBA)
+			}
+			compareGolden(t, tt.name, got)
+		})
+	}
+}
+
+func TestRenderPNG(t *testing.T) {
+	gm := newTestGame(t, DefaultOptions())
+	winW, winH := ebiten.WindowSize()
+	path := filepath.Join(t.TempDir(), "gopher.png")
+	if err := gm.renderPNG(parseMessage("Hello, Gopher!\nこんにちは"), path); err != nil {
+		t.Fatal(err)
+	}
+	if w, h := ebiten.WindowSize(); w != winW || h != winH {
+		t.Errorf("window size = %dx%d, want it unchanged at %dx%d", w, h, winW, winH)
+	}
+	img, err := readPNG(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, h := gm.physicalSize(gm.screenWidth, gm.screenHeight)
+	if got, want := img.Bounds(), image.Rect(0, 0, w, h); got != want {
+		t.Errorf("bounds = %v, want the window size %v", got, want)
+	}
+}
+
+func TestDrawProgress(t *testing.T) {
+	gm := newTestGame(t, DefaultOptions())
+	gm.showMessage(parseMessage(`{"text":"Building","progress":0.5}`))
+	gm.progress = gm.progressTarget // バーが動き終えた状態にする
+
+	img := ebiten.NewImage(gm.physicalSize(gm.screenWidth, gm.screenHeight))
+	defer img.Deallocate()
+	gm.drawProgress(img, gm.layout)
+	got := readImage(img)
+
+	// バーの縦の中央の行で、塗った部分と未完了の部分の画素を数える
+	ly, cfg, s := gm.layout, gm.layoutCfg, float32(gm.deviceScale)
+	y := int((ly.bubbleY + ly.bubbleH - float32(cfg.BubblePadY)/2 - progressBarH/2) * s)
+	var filled, track int
+	for x := range got.Bounds().Dx() {
+		switch got.RGBAAt(x, y) {
+		case progressFillColor:
+			filled++
+		case progressTrackColor:
+			track++
+		}
+	}
+	barW := float64(ly.bubbleW-float32(cfg.BubblePadX)) * gm.deviceScale
+	if math.Abs(float64(filled)-barW/2) > 2 || math.Abs(float64(track)-barW/2) > 2 {
+		t.Errorf("filled %d px and track %d px of a %v px bar, want about half each", filled, track, barW)
+	}
+}
+
+func TestDrawBubbleAntiAlias(t *testing.T) {
+	for _, aa := range []bool{true, false} {
+		t.Run(fmt.Sprintf("antialias=%v", aa), func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.AntiAlias = aa
+			gm := newTestGame(t, opts)
+			if gm.antiAlias != aa {
+				t.Fatalf("antiAlias = %v, want %v", gm.antiAlias, aa)
+			}
+			gm.placement = placementAbove
+			gm.showMessage(parseMessage("Hello, Gopher!"))
+
+			// 文字は描かず、吹き出しの塗り・枠線・しっぽだけで縁の画素を数える
+			img := ebiten.NewImage(gm.physicalSize(gm.screenWidth, gm.screenHeight))
+			defer img.Deallocate()
+			gm.drawBubble(img, gm.layout)
+			got := readImage(img)
+			var blended int
+			for i := 3; i < len(got.Pix); i += 4 {
+				if a := got.Pix[i]; a != 0 && a != 0xff {
+					blended++
+				}
+			}
+			if aa && blended == 0 {
+				t.Error("no blended edge pixels with anti-aliasing on")
+			}
+			if !aa && blended != 0 {
+				t.Errorf("%d blended edge pixels with anti-aliasing off, want 0", blended)
+			}
+		})
+	}
+}
+
+func TestDrawTextColor(t *testing.T) {
+	want := color.RGBA{0xd8, 0x1b, 0x60, 0xff}
+	gm := newTestGame(t, DefaultOptions())
+	gm.showMessage(parseMessage(`{"text":"HHHH","color":"#d81b60"}`))
+	gm.revealedChars = gm.layout.charCount()
+
+	img := ebiten.NewImage(gm.screenWidth, gm.screenHeight)
+	defer img.Deallocate()
+	gm.drawText(img, gm.layout)
+	got := readImage(img)
+
+	// 縁のアンチエイリアスで混ざった画素を除き、不透明な文字の画素の色を確かめる
+	var n int
+	for y := range got.Bounds().Dy() {
+		for x := range got.Bounds().Dx() {
+			c := got.RGBAAt(x, y)
+			if c.A != 0xff {
+				continue
+			}
+			n++
+			if c != want {
+				t.Fatalf("glyph pixel (%d, %d) = %v, want %v", x, y, c, want)
+			}
+		}
+	}
+	if n == 0 {
+		t.Error("no opaque glyph pixels drawn")
+	}
+}
+
+// inkBounds は img の透明でない画素を囲む矩形を返す。
+func inkBounds(img *image.RGBA) image.Rectangle {
+	var r image.Rectangle
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if img.RGBAAt(x, y).A > 0 {
+				r = r.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return r
+}
+
+func TestDrawTextAlign(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Align = "center"
+	gm := newTestGame(t, opts)
+	gm.showMessage(parseMessage(`hi\na much longer second line`))
+	// 1行目の短い行だけを描く。揃え位置は行全体の幅で決まるため、表示途中でも位置は変わらない
+	gm.revealedChars = len("hi")
+
+	img := ebiten.NewImage(gm.screenWidth, gm.screenHeight)
+	defer img.Deallocate()
+	gm.drawText(img, gm.layout)
+	ink := inkBounds(readImage(img))
+	if ink.Empty() {
+		t.Fatal("no text drawn")
+	}
+
+	// 短い行は吹き出しの文字領域の左右中央に置く
+	ly := gm.layout
+	textX := float64(ly.bubbleX) + bubblePadX/2 - 2
+	textW := float64(ly.bubbleW) - bubblePadX
+	leftGap := float64(ink.Min.X) - textX
+	rightGap := textX + textW - float64(ink.Max.X)
+	if math.Abs(leftGap-rightGap) > 2 {
+		t.Errorf("gaps = %v left and %v right, want them equal", leftGap, rightGap)
+	}
+}
+
+func TestDrawBubbleColors(t *testing.T) {
+	fill := color.RGBA{0x33, 0x66, 0x99, 0xff}
+	gm := newTestGame(t, DefaultOptions())
+	gm.bubbleFill = fill
+	gm.enqueue(message{Text: "Hello, Gopher!"})
+	updateFrames(t, gm, 1)
+
+	img := ebiten.NewImage(gm.screenWidth, gm.screenHeight)
+	defer img.Deallocate()
+	gm.drawBubble(img, gm.layout)
+	got := readImage(img)
+
+	ly := gm.layout
+	cx := int(ly.bubbleX + ly.bubbleW/2)
+	cy := int(ly.bubbleY + ly.bubbleH/2)
+	if c := got.RGBAAt(cx, cy); c != fill {
+		t.Errorf("pixel inside the bubble = %v, want the fill %v", c, fill)
+	}
+}
+
+func TestDrawBubbleTailSize(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, length float64
+		below         bool
+	}{
+		{"default", tailWidth, tailLength, false},
+		{"large", 60, 50, false},
+		{"large below", 60, 50, true},
+		{"disabled", tailWidth, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.TailWidth, opts.TailLength = tt.width, tt.length
+			gm := newTestGame(t, opts)
+			gm.placement = placementAbove
+			if tt.below {
+				gm.placement = placementBelow
+			}
+			gm.showMessage(parseMessage("Hello, Gopher!"))
+
+			img := ebiten.NewImage(gm.physicalSize(gm.screenWidth, gm.screenHeight))
+			defer img.Deallocate()
+			gm.drawBubble(img, gm.layout)
+			got := readImage(img)
+
+			// 付け根から先端へ向かう線上の点を、しっぽの中ほどと先端の先で調べる
+			ly := gm.layout
+			x, y := ly.tailBase()
+			m, v := float32(1), float32(1)
+			if ly.tailDir == tailLeft {
+				m = -1
+			}
+			if ly.bubbleBelow {
+				v = -1
+			}
+			at := func(d float32) color.RGBA {
+				s := float32(gm.deviceScale)
+				return got.RGBAAt(int((x-d*3/4*m)*s), int((y+d*v)*s))
+			}
+			l := float32(tt.length)
+			if l > 0 {
+				if c := at(l / 2); c.A < 0x80 {
+					t.Errorf("pixel halfway along the tail = %v, want opaque", c)
+				}
+			} else if c := at(tailLength / 2); c.A != 0 {
+				t.Errorf("pixel where the default tail would be = %v, want transparent", c)
+			}
+			if c := at(l + 10); c.A != 0 {
+				t.Errorf("pixel past the tail tip = %v, want transparent", c)
+			}
+		})
+	}
+}
+
+func TestDrawBubbleThickBorderCorner(t *testing.T) {
+	fill := color.RGBA{0x33, 0x66, 0x99, 0xff}
+	stroke := color.RGBA{0xff, 0xcc, 0x00, 0xff}
+	const width = 8
+	opts := DefaultOptions()
+	opts.BubbleFill, opts.BubbleStroke, opts.StrokeWidth = fill, stroke, width
+	gm := newTestGame(t, opts)
+	gm.placement = placementAbove
+	gm.showMessage(parseMessage("Hello, Gopher!"))
+
+	img := ebiten.NewImage(gm.physicalSize(gm.screenWidth, gm.screenHeight))
+	defer img.Deallocate()
+	gm.drawBubble(img, gm.layout)
+	got := readImage(img)
+
+	// 左上の角丸の中心から斜め外へ、中心からの距離 d の画素を調べる。
+	// 枠線の外側の縁は既定の太さのときと同じ r+1 に保たれ、内側に width の太さで描かれる
+	ly := gm.layout
+	r := float64(ly.bubbleRadius)
+	cx, cy := float64(ly.bubbleX)+r, float64(ly.bubbleY)+r
+	at := func(d float64) color.RGBA {
+		s := gm.deviceScale
+		return got.RGBAAt(int((cx-d/math.Sqrt2)*s), int((cy-d/math.Sqrt2)*s))
+	}
+	if c := at(r + 1 - width/2); c != stroke {
+		t.Errorf("pixel in the middle of the corner border = %v, want the stroke %v", c, stroke)
+	}
+	if c := at(r + 1 - width - 3); c != fill {
+		t.Errorf("pixel inside the corner border = %v, want the fill %v", c, fill)
+	}
+	if c := at(r + 3); c.A != 0 {
+		t.Errorf("pixel outside the corner = %v, want transparent", c)
+	}
+}
+
+func TestDrawShadow(t *testing.T) {
+	for _, offset := range []float64{0, 8} {
+		t.Run(fmt.Sprint(offset), func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.ShadowOffset = offset
+			gm := newTestGame(t, opts)
+			gm.showMessage(parseMessage("Hello, Gopher!"))
+
+			img := ebiten.NewImage(gm.screenWidth, gm.screenHeight)
+			defer img.Deallocate()
+			gm.drawBubble(img, gm.layout)
+			got := readImage(img)
+
+			// 吹き出しの右端のすぐ外側は、影がなければ透明で、影があれば半透明の黒になる
+			ly := gm.layout
+			c := got.RGBAAt(int(ly.bubbleX+ly.bubbleW)+5, int(ly.bubbleY+ly.bubbleH/2))
+			var wantA uint8
+			if offset > 0 {
+				wantA = uint8(math.Round(defaultShadowOpacity * 0xff))
+			}
+			if c.R != 0 || c.G != 0 || c.B != 0 || math.Abs(float64(c.A)-float64(wantA)) > 4 {
+				t.Errorf("pixel beside the bubble = %v, want black with alpha %d", c, wantA)
+			}
+		})
+	}
+}
+
+func TestDrawOpacity(t *testing.T) {
+	tests := []struct {
+		opacity float64
+		wantA   uint8
+	}{
+		{0.5, 0x80},
+		{2, 0xff}, // 範囲外の値は 0〜1 に収める
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.opacity), func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Opacity = tt.opacity
+			gm := newTestGame(t, opts)
+			gm.showMessage(parseMessage("Hello, Gopher!"))
+			gm.bubbleAlpha = 1
+
+			img := ebiten.NewImage(gm.screenWidth, gm.screenHeight)
+			defer img.Deallocate()
+			gm.Draw(img)
+			got := readImage(img)
+
+			// 吹き出しの白い塗りに、ウィンドウ全体の不透明度が掛かる
+			ly := gm.layout
+			c := got.RGBAAt(int(ly.bubbleX+ly.bubbleW/2), int(ly.bubbleY+4))
+			if d := int(c.A) - int(tt.wantA); d < -2 || d > 2 || c.R != c.A {
+				t.Errorf("bubble pixel = %v, want white with alpha %d", c, tt.wantA)
+			}
+		})
+	}
+}
+
+func TestDrawBackground(t *testing.T) {
+	green := color.RGBA{0, 0xff, 0, 0xff}
+	tests := []struct {
+		name       string
+		background color.Color
+		want       color.RGBA
+	}{
+		{"transparent", nil, color.RGBA{}},
+		{"green", green, green},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Background = tt.background
+			gm := newTestGame(t, opts)
+
+			img := ebiten.NewImage(gm.screenWidth, gm.screenHeight)
+			defer img.Deallocate()
+			gm.Draw(img)
+			// Gopherも吹き出しもない左上の隅は背景のまま
+			if got := readImage(img).RGBAAt(0, 0); got != tt.want {
+				t.Errorf("corner pixel = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDrawTextVerticalCenter(t *testing.T) {
+	for _, size := range []int{16, 32} {
+		t.Run(fmt.Sprint(size), func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.FontSize = size
+			gm := newTestGame(t, opts)
+			gm.showMessage(parseMessage(`HHH\nHHH`))
+			gm.revealedChars = gm.layout.charCount()
+
+			img := ebiten.NewImage(gm.screenWidth, gm.screenHeight)
+			defer img.Deallocate()
+			gm.drawText(img, gm.layout)
+			ink := inkBounds(readImage(img))
+			if ink.Empty() {
+				t.Fatal("no text drawn")
+			}
+
+			// 1行目の大文字の上端から最終行のディセンダーの下端までが、吹き出しの上下中央に来る。
+			// H にはディセンダーがないため、描かれた下端はベースラインになる
+			ly := gm.layout
+			descent := gm.fontFace.Metrics().HDescent
+			topGap := float64(ink.Min.Y) - float64(ly.bubbleY)
+			bottomGap := float64(ly.bubbleY+ly.bubbleH) - (float64(ink.Max.Y) + descent)
+			if math.Abs(topGap-bottomGap) > 2 {
+				t.Errorf("gaps = %v above and %v below the text, want them equal", topGap, bottomGap)
+			}
+		})
+	}
+}
+
+// tailRect はしっぽと、その付け根の吹き出しの縁を囲む矩形を返す。
+func tailRect(ly layout, cfg LayoutConfig) image.Rectangle {
+	x := int(ly.tailX)
+	y := int(ly.bubbleY + ly.bubbleH)
+	if ly.bubbleBelow {
+		y = int(ly.bubbleY)
+	}
+	w, h := int(cfg.TailWidth)*2, int(cfg.TailLength)+10
+	return image.Rect(x-w, y-h, x+w, y+h)
+}
+
+// compareGolden は got を testdata/golden/<name>.png と比べる。-update の場合は got で書き換える。
+func compareGolden(t *testing.T, name string, got *image.RGBA) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name+".png")
+	if *update {
+		if err := writePNG(path, got); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := readPNG(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if err := diffImages(got, want); err != nil {
+		t.Errorf("%s: %v (run go test -update if the change is intended)", path, err)
+	}
+}
+
+// diffImages は2つの画像が大きさが同じで、許す差を超えた画素が goldenMaxDiff の割合以下なら nil を返す。
+func diffImages(got *image.RGBA, want image.Image) error {
+	gb, wb := got.Bounds(), want.Bounds()
+	if gb.Size() != wb.Size() {
+		return fmt.Errorf("size = %v, want %v", gb.Size(), wb.Size())
+	}
+	var diff int
+	for y := range gb.Dy() {
+		for x := range gb.Dx() {
+			g := got.RGBAAt(gb.Min.X+x, gb.Min.Y+y)
+			wr, wg, wbl, wa := want.At(wb.Min.X+x, wb.Min.Y+y).RGBA()
+			w := [4]int{int(wr >> 8), int(wg >> 8), int(wbl >> 8), int(wa >> 8)}
+			for i, c := range [4]int{int(g.R), int(g.G), int(g.B), int(g.A)} {
+				if d := c - w[i]; d > goldenTolerance || d < -goldenTolerance {
+					diff++
+					break
+				}
+			}
+		}
+	}
+	if total := gb.Dx() * gb.Dy(); float64(diff) > float64(total)*goldenMaxDiff {
+		return fmt.Errorf("%d of %d pixels differ", diff, total)
+	}
+	return nil
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return img, nil
+}
+
+func writePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,101 @@
+package mascot
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// windowRect はウィンドウの画面上の位置とサイズ。
+type windowRect struct {
+	x, y, w, h int
+}
+
+// resizeTween はウィンドウのサイズと位置を数フレームかけて変えるアニメーション。
+// 始点と終点で固定する角の座標は等しいため、補間の途中でも角の位置は動かない。
+type resizeTween struct {
+	from, to windowRect
+	frame    int
+	frames   int
+}
+
+// current は現在のフレームのウィンドウの位置とサイズを返す。変化は終わりに向けて緩やかになる。
+func (t *resizeTween) current() windowRect {
+	p := float64(t.frame) / float64(t.frames)
+	p = 1 - math.Pow(1-p, 3)
+	lerp := func(a, b int) int {
+		return a + int(math.Round(float64(b-a)*p))
+	}
+	return windowRect{
+		x: lerp(t.from.x, t.to.x),
+		y: lerp(t.from.y, t.to.y),
+		w: lerp(t.from.w, t.to.w),
+		h: lerp(t.from.h, t.to.h),
+	}
+}
+
+// windowPosition はウィンドウの位置を返す。リサイズのアニメーション中は最終的な位置を返す。
+func (gm *Game) windowPosition() (int, int) {
+	if gm.resize != nil {
+		return gm.resize.to.x, gm.resize.to.y
+	}
+	return ebiten.WindowPosition()
+}
+
+// resizeWindow はウィンドウを to の位置とサイズに変える。
+// リサイズにかける秒数が設定されていれば、現在の位置とサイズからアニメーションさせる。
+func (gm *Game) resizeWindow(to windowRect) {
+	frames := int(gm.resizeSec * float64(ebiten.TPS()))
+	if frames <= 0 {
+		gm.resize = nil
+		ebiten.SetWindowSize(to.w, to.h)
+		ebiten.SetWindowPosition(to.x, to.y)
+		return
+	}
+
+	var from windowRect
+	if gm.resize != nil {
+		from = gm.resize.current()
+	} else {
+		from.x, from.y = ebiten.WindowPosition()
+		from.w, from.h = ebiten.WindowSize()
+	}
+	gm.resize = &resizeTween{from: from, to: to, frames: frames}
+}
+
+// updateResize はリサイズのアニメーションを1フレーム進める。
+func (gm *Game) updateResize() {
+	if gm.resize == nil {
+		return
+	}
+	gm.resize.frame++
+	r := gm.resize.current()
+	ebiten.SetWindowSize(r.w, r.h)
+	ebiten.SetWindowPosition(r.x, r.y)
+	if gm.resize.frame >= gm.resize.frames {
+		gm.resize = nil
+	}
+}
+
+// finishResize はリサイズのアニメーションを終わらせ、最終的な位置とサイズにする。
+func (gm *Game) finishResize() {
+	if gm.resize == nil {
+		return
+	}
+	gm.resize.frame = gm.resize.frames
+	gm.updateResize()
+}
+
+// drawResizing はアニメーション中の画面を描画する。
+// 中身は最終的なサイズで描画し、画面上の位置が動かないよう現在のウィンドウとの差だけずらして切り取る。
+func (gm *Game) drawResizing(screen *ebiten.Image, draw func(*ebiten.Image)) {
+	w, h := gm.physicalSize(gm.screenWidth, gm.screenHeight)
+	layer := ensureLayer(&gm.resizeLayer, w, h)
+	layer.Clear()
+	draw(layer)
+
+	cur := gm.resize.current()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(gm.resize.to.x-cur.x)*gm.deviceScale, float64(gm.resize.to.y-cur.y)*gm.deviceScale)
+	screen.DrawImage(layer, op)
+}
@@ -0,0 +1,46 @@
+package mascot
+
+import (
+	"strings"
+	"unicode"
+)
+
+// script はメッセージの主な文字体系。折り返しの規則とフォントの選択に使う。
+type script int
+
+const (
+	scriptLatin script = iota // ラテン文字など。単語単位で折り返す
+	scriptCJK                 // 日本語・中国語・韓国語。文字単位で折り返し、行頭禁則を守る
+)
+
+// cjkScriptShare は、かなを含まないメッセージを CJK とみなす、文字のうち漢字・ハングルが占める割合の下限。
+const cjkScriptShare = 0.3
+
+// detectScript は文字の種類の数からメッセージの主な文字体系を推定する。
+// かなが1文字でもあれば日本語として CJK とし、それ以外は漢字・ハングルの割合が cjkScriptShare 以上なら CJK とする。
+func detectScript(s string) script {
+	var letters, cjk int
+	for _, r := range s {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			return scriptCJK
+		case unicode.In(r, unicode.Han, unicode.Hangul):
+			cjk++
+			letters++
+		case unicode.IsLetter(r):
+			letters++
+		}
+	}
+	if cjk > 0 && float64(cjk) >= float64(letters)*cjkScriptShare {
+		return scriptCJK
+	}
+	return scriptLatin
+}
+
+// lineStartForbidden は CJK の行頭に置かない文字（閉じ括弧・句読点・小書きのかな・長音記号など）。
+const lineStartForbidden = "、。，．・：；？！ー～）」』】〕〉》］｝ぁぃぅぇぉっゃゅょゎァィゥェォッャュョヮヵヶ"
+
+// noLineStart は r を CJK の行頭に置いてはいけないかどうかを返す。
+func noLineStart(r rune) bool {
+	return strings.ContainsRune(lineStartForbidden, r)
+}
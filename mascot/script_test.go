@@ -0,0 +1,29 @@
+package mascot
+
+import "testing"
+
+func TestDetectScript(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want script
+	}{
+		{"english", "Hello, Gopher!", scriptLatin},
+		{"japanese", "こんにちは、世界。", scriptCJK},
+		{"kanji only", "日本語", scriptCJK},
+		{"mixed with kana", "Goは楽しい", scriptCJK},
+		{"english with a kana", "Build finished: OK ね", scriptCJK},
+		{"english with a few kanji", "The word for mountain is 山 in Japanese", scriptLatin},
+		{"chinese", "你好，世界", scriptCJK},
+		{"korean", "안녕하세요", scriptCJK},
+		{"digits and symbols", "1 + 2 = 3", scriptLatin},
+		{"empty", "", scriptLatin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectScript(tt.s); got != tt.want {
+				t.Errorf("detectScript(%q) = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
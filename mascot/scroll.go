@@ -0,0 +1,45 @@
+package mascot
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// scrollSpeed は自動スクロールの1フレームあたりの最大移動量(px)。
+const scrollSpeed = 4
+
+// maxScroll はテキストのスクロール量の最大値を返す。スクロールしない場合は0。
+func (gm *Game) maxScroll() float64 {
+	return max(gm.layout.textH-gm.layout.textViewH, 0)
+}
+
+// updateScroll は吹き出しに収まらないテキストをスクロールする。
+// 吹き出しの上でホイールを回すと手動でスクロールし、それまではタイプライター表示の最新の行が
+// 見えるよう自動でスクロールする。
+func (gm *Game) updateScroll(cx, cy int) {
+	limit := gm.maxScroll()
+	if !gm.hasMessage || limit == 0 {
+		gm.scrollY = 0
+		return
+	}
+
+	if _, dy := ebiten.Wheel(); dy != 0 && gm.hitBubble(cx, cy) {
+		gm.scrollY -= dy * gm.layout.lineHeight
+		gm.scrollManual = true
+	} else if !gm.scrollManual {
+		target := float64(gm.revealedLine()+1)*gm.layout.lineHeight - gm.layout.textViewH
+		if target > gm.scrollY {
+			gm.scrollY += min(target-gm.scrollY, scrollSpeed)
+		}
+	}
+	gm.scrollY = min(max(gm.scrollY, 0), limit)
+}
+
+// revealedLine はタイプライター表示で最後に表示した文字のある行の番号を返す。
+func (gm *Game) revealedLine() int {
+	remaining := gm.revealedChars
+	for i, line := range gm.layout.lines {
+		remaining -= len([]rune(line))
+		if remaining <= 0 {
+			return i
+		}
+	}
+	return len(gm.layout.lines) - 1
+}
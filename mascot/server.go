@@ -0,0 +1,101 @@
+package mascot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// maxMessageBodySize は HTTP で受け付けるメッセージ本文の最大バイト数。
+const maxMessageBodySize = 64 << 10
+
+// startHTTPServer は addr が指定されていればメッセージ受信用の HTTP サーバーを起動する。
+// ホストを省略したアドレス（":8080" など）はループバックにのみバインドする。
+// ハンドラはループバック以外の Host を断るため、ループバック以外のアドレスは起動時にエラーにする。
+func (gm *Game) startHTTPServer(addr string) error {
+	if addr == "" {
+		return nil
+	}
+	if strings.HasPrefix(addr, ":") {
+		addr = "127.0.0.1" + addr
+	}
+	if !isLoopbackHost(addr) {
+		return fmt.Errorf("http address %s is not a loopback address", addr)
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen %s: %w", addr, err)
+	}
+
+	srv := &http.Server{Handler: gm.messageHandler()}
+	gm.closers = append(gm.closers, srv.Close)
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "http server: %v\n", err)
+		}
+	}()
+	return nil
+}
+
+// messageHandler は POST /message で受け取ったテキストをメッセージの待ち行列に追加する。
+// 本文はプレーンテキスト、または Content-Type が application/json の場合は標準入力と同じJSON形式として解釈する。
+// ブラウザで開いたページからの送信を防ぐため、Origin ヘッダーのある要求と、
+// DNS リバインディングを防ぐため、Host がループバックでない要求は 403 で断る。
+// ハンドラは HTTP サーバーのgoroutineで動くが、待ち行列は mu で保護されているため
+// 標準入力のgoroutineと同時に追加しても、到着した順に表示される。
+func (gm *Game) messageHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /message", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Origin") != "" || !isLoopbackHost(r.Host) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxMessageBodySize))
+		if err != nil {
+			http.Error(w, "read body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		msg := message{Text: string(body)}
+		if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "application/json" {
+			msg = message{}
+			if err := json.Unmarshal(body, &msg); err != nil {
+				http.Error(w, "decode json: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		// 記法や ANSI エスケープシーケンスを取り除くと表示する文字が残らない本文も、待ち行列で捨てずにここで断る
+		msg.Text = strings.TrimRight(msg.Text, "\r\n")
+		if msg.blank() {
+			http.Error(w, "empty message", http.StatusBadRequest)
+			return
+		}
+
+		gm.enqueue(msg)
+		w.WriteHeader(http.StatusAccepted)
+	})
+	return mux
+}
+
+// isLoopbackHost は Host ヘッダーの値が localhost かループバックアドレスを指すかを返す。
+func isLoopbackHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		// ポートを省略した Host
+		host = strings.TrimSuffix(strings.TrimPrefix(hostport, "["), "]")
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
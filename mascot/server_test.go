@@ -0,0 +1,80 @@
+package mascot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMessageHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		host        string // 空なら 127.0.0.1:8080
+		origin      string
+		wantStatus  int
+		wantText    string
+	}{
+		{"plain text", "text/plain", "hello\n", "", "", http.StatusAccepted, "hello"},
+		{"json", "application/json", `{"text":"hi","style":"think"}`, "", "", http.StatusAccepted, "hi"},
+		{"empty", "text/plain", "", "", "", http.StatusBadRequest, ""},
+		{"whitespace only", "text/plain", " \r\n", "", "", http.StatusBadRequest, ""},
+		{"markup only", "text/plain", "****", "", "", http.StatusBadRequest, ""},
+		{"ansi only", "text/plain", "\x1b[32m\x1b[0m", "", "", http.StatusBadRequest, ""},
+		{"empty json text", "application/json", `{"text":"**"}`, "", "", http.StatusBadRequest, ""},
+		{"invalid json", "application/json", `{"text":`, "", "", http.StatusBadRequest, ""},
+		{"localhost", "text/plain", "hello", "localhost:8080", "", http.StatusAccepted, "hello"},
+		{"ipv6 loopback", "text/plain", "hello", "[::1]:8080", "", http.StatusAccepted, "hello"},
+		{"cross-origin", "text/plain", "hello", "", "https://example.com", http.StatusForbidden, ""},
+		{"rebound host", "text/plain", "hello", "evil.example.com:8080", "", http.StatusForbidden, ""},
+		{"lan host", "text/plain", "hello", "192.168.0.2:8080", "", http.StatusForbidden, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm := &Game{}
+			req := httptest.NewRequest(http.MethodPost, "/message", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			req.Host = "127.0.0.1:8080"
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+			gm.messageHandler().ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %q)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			msg, ok := gm.dequeue()
+			if ok != (tt.wantText != "") || msg.Text != tt.wantText {
+				t.Errorf("queued message = %q, %v, want %q", msg.Text, ok, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestStartHTTPServerNonLoopback(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    string
+		wantErr bool
+	}{
+		{"no host", ":0", false},
+		{"localhost", "localhost:0", false},
+		{"all interfaces", "0.0.0.0:0", true},
+		{"lan address", "192.168.0.2:0", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm := &Game{}
+			err := gm.startHTTPServer(tt.addr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("startHTTPServer(%q) error = %v, wantErr %v", tt.addr, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package mascot
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 影の描画パラメータ
+const (
+	shadowBlur           = 2   // ぼかすためにずらして重ね塗りする幅(px)。影の縁はこの幅だけ薄くなる
+	defaultShadowOpacity = 0.3 // 影の既定の不透明度
+)
+
+// drawShadow は吹き出しのパス bp を右下に shadowOffset ずらした位置に、半透明の黒で影を描く。
+// 上下左右に少しずつずらして重ね塗りし、縁をぼかす。重なりきった中央の不透明度が shadowOpacity になる。
+func (gm *Game) drawShadow(dst *ebiten.Image, bp *vector.Path) {
+	if gm.shadowOffset <= 0 || gm.shadowOpacity <= 0 {
+		return
+	}
+	const passes = 9 // 3x3 の位置で塗る
+	alpha := 1 - math.Pow(1-math.Min(gm.shadowOpacity, 1), 1.0/passes)
+	clr := colorScale(color.NRGBA{A: uint8(math.Round(alpha * 0xff))})
+
+	s := gm.deviceScale
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			op := &vector.AddPathOptions{}
+			op.GeoM.Translate(gm.shadowOffset+float64(dx*shadowBlur), gm.shadowOffset+float64(dy*shadowBlur))
+			op.GeoM.Scale(s, s)
+			var sp vector.Path
+			sp.AddPath(bp, op)
+			vector.FillPath(dst, &sp, nil, &vector.DrawPathOptions{AntiAlias: gm.antiAlias, ColorScale: clr})
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package mascot
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+)
+
+// startCommandServer は path が指定されていれば、Unixドメインソケットでコマンドを受け付ける。
+// 1行1コマンドで、複数のクライアントが同時に接続できる。
+//
+//	MSG <text>   メッセージを表示する（標準入力の1行と同じ形式）
+//	CLEAR        表示中のメッセージを消す
+//	IMG <path>   Gopher画像を差し替える
+//	QUIT         アプリケーションを終了する
+//
+// 各コマンドには "OK" または "ERR <理由>" の1行で応答する。
+// bufio.MaxScanTokenSize を超える行には "ERR" を応答して接続を閉じる。
+func (gm *Game) startCommandServer(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	// 前回の異常終了で残ったソケットファイルを削除する
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove stale socket %s: %w", path, err)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("listen %s: %w", path, err)
+	}
+
+	gm.closers = append(gm.closers, ln.Close)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					fmt.Fprintf(os.Stderr, "accept %s: %v\n", path, err)
+				}
+				return
+			}
+			go gm.serveCommands(conn)
+		}
+	}()
+	return nil
+}
+
+// serveCommands は接続からコマンドを読み込んで実行し、結果を応答する。
+func (gm *Game) serveCommands(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		reply := "OK"
+		quit, err := gm.execCommand(scanner.Text())
+		if err != nil {
+			reply = "ERR " + err.Error()
+		}
+		if _, err := fmt.Fprintln(conn, reply); err != nil || quit {
+			return
+		}
+	}
+	// 長すぎる行は読み飛ばせないため、理由を応答してから接続を閉じる
+	if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		fmt.Fprintf(conn, "ERR line longer than %d bytes\n", bufio.MaxScanTokenSize)
+	}
+}
+
+// execCommand は1行のコマンドを実行する。QUIT の場合は true を返す。
+func (gm *Game) execCommand(line string) (bool, error) {
+	cmd, arg, _ := strings.Cut(line, " ")
+	switch strings.ToUpper(cmd) {
+	case "MSG":
+		if strings.TrimSpace(arg) == "" {
+			return false, errors.New("empty message")
+		}
+		gm.enqueue(parseMessage(arg))
+	case "CLEAR":
+		gm.Clear()
+	case "IMG":
+		if arg == "" {
+			return false, errors.New("missing image path")
+		}
+		return false, gm.SetGopherImage(arg)
+	case "QUIT":
+		gm.Quit()
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown command %q", cmd)
+	}
+	return false, nil
+}
@@ -0,0 +1,110 @@
+package mascot
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sendCommands はソケットに接続して lines を1行ずつ送り、それぞれへの応答を返す。
+func sendCommands(path string, lines ...string) ([]string, error) {
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	r := bufio.NewReader(conn)
+	var replies []string
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(conn, line); err != nil {
+			return replies, err
+		}
+		reply, err := r.ReadString('\n')
+		if err != nil {
+			return replies, err
+		}
+		replies = append(replies, strings.TrimSuffix(reply, "\n"))
+	}
+	return replies, nil
+}
+
+func newSocketTestGame(t *testing.T) (*Game, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "gopher.sock")
+	opts := DefaultOptions()
+	opts.SocketPath = path
+	return newTestGame(t, opts), path
+}
+
+func TestCommandSocket(t *testing.T) {
+	gm, path := newSocketTestGame(t)
+	gm.enqueue(message{Text: "first"})
+	updateFrames(t, gm, 1)
+	if !gm.hasMessage {
+		t.Fatal("first message is not shown")
+	}
+
+	// CLEAR と QUIT は Update の中で実行されるため、応答を待つ間は予約された処理を実行する
+	type result struct {
+		replies []string
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		replies, err := sendCommands(path, "MSG hello", "CLEAR", "NOPE", "QUIT")
+		done <- result{replies, err}
+	}()
+	var res result
+	deadline := time.After(5 * time.Second)
+wait:
+	for {
+		select {
+		case res = <-done:
+			break wait
+		case <-deadline:
+			t.Fatal("no replies from the socket")
+		default:
+			gm.runTasks()
+			time.Sleep(time.Millisecond)
+		}
+	}
+	if res.err != nil {
+		t.Fatal(res.err)
+	}
+
+	want := []string{"OK", "OK", `ERR unknown command "NOPE"`, "OK"}
+	if !reflect.DeepEqual(res.replies, want) {
+		t.Errorf("replies = %q, want %q", res.replies, want)
+	}
+	if gm.hasMessage {
+		t.Error("CLEAR did not hide the current message")
+	}
+	if got := queuedTexts(gm); !reflect.DeepEqual(got, []string{"hello"}) {
+		t.Errorf("queued messages = %q, want %q", got, []string{"hello"})
+	}
+	if !gm.quit {
+		t.Error("QUIT did not stop the game")
+	}
+}
+
+func TestCommandSocketLongLine(t *testing.T) {
+	gm, path := newSocketTestGame(t)
+
+	replies, err := sendCommands(path, "MSG "+strings.Repeat("a", bufio.MaxScanTokenSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replies) != 1 || !strings.HasPrefix(replies[0], "ERR ") {
+		t.Errorf("replies = %q, want an ERR reply", replies)
+	}
+	if n := len(gm.msgQueue); n != 0 {
+		t.Errorf("%d messages queued, want 0", n)
+	}
+}
@@ -0,0 +1,77 @@
+package mascot
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+	"github.com/hajimehoshi/ebiten/v2/audio/wav"
+)
+
+//go:embed assets/pop.wav
+var popWAV []byte
+
+// soundSampleRate は音声を再生するサンプリングレート。
+const soundSampleRate = 44100
+
+// loadSoundPlayer はメッセージの通知音を再生する Player を作る。
+// path が空の場合は埋め込みの通知音を使う。WAV と Ogg Vorbis に対応する。
+// 連続してメッセージが届いても音が重ならないよう、同じ Player を巻き戻して再生する。
+func loadSoundPlayer(path string) (*audio.Player, error) {
+	data, name := popWAV, "default"
+	if path != "" {
+		name = path
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read sound %s: %w", path, err)
+		}
+	}
+
+	var s io.Reader
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".ogg") {
+		s, err = vorbis.DecodeWithSampleRate(soundSampleRate, bytes.NewReader(data))
+	} else {
+		s, err = wav.DecodeWithSampleRate(soundSampleRate, bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decode sound %s: %w", name, err)
+	}
+	pcm, err := io.ReadAll(s)
+	if err != nil {
+		return nil, fmt.Errorf("decode sound %s: %w", name, err)
+	}
+	// 音声コンテキストはプロセスに1つしか作れない
+	ctx := audio.CurrentContext()
+	if ctx == nil {
+		ctx = audio.NewContext(soundSampleRate)
+	}
+	return ctx.NewPlayerFromBytes(pcm), nil
+}
+
+// toggleMute は通知音のミュートを切り替える。
+func (gm *Game) toggleMute() {
+	gm.muted = !gm.muted
+	if gm.onToggle != nil {
+		gm.onToggle()
+	}
+}
+
+// playSound は通知音を最初から再生する。ミュート中は何もしない。
+func (gm *Game) playSound() {
+	if gm.muted || gm.sound == nil {
+		return
+	}
+	if err := gm.sound.Rewind(); err != nil {
+		fmt.Fprintf(os.Stderr, "rewind sound: %v\n", err)
+		return
+	}
+	gm.sound.Play()
+}
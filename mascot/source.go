@@ -0,0 +1,126 @@
+package mascot
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// defaultClearSentinel は、入力されると表示中のメッセージを消す既定の行。
+// 通常のテキストと重ならないよう NUL 文字で始める（printf '\0CLEAR\n' で送れる）。
+const defaultClearSentinel = "\x00CLEAR"
+
+// InputSource はメッセージの入力元。Messages が返すチャネルから受け取った文字列を1行ずつメッセージとして表示する。
+// 空文字列は無視し、JSON のメッセージも受け付ける。チャネルを閉じると、その入力元は終わったものとみなす。
+type InputSource interface {
+	Messages() <-chan string
+}
+
+// ReaderSource は R から1行ずつ読み込む InputSource。
+// 読み込みに失敗した場合や、行が MaxLineBytes より長い場合はエラーを表示して読み込みをやめる。
+// Game の Close で R が io.Closer なら閉じ、読み込み中の goroutine を止める。
+type ReaderSource struct {
+	R            io.Reader
+	MaxLineBytes int // 1行の最大バイト数。0 で既定の 1MiB
+}
+
+// StdinSource は標準入力から1行ずつ読み込む InputSource を返す。
+func StdinSource() InputSource {
+	return ReaderSource{R: os.Stdin}
+}
+
+// Messages は R を読み込む goroutine を開始し、読み込んだ行を送るチャネルを返す。R の終わりでチャネルを閉じる。
+func (s ReaderSource) Messages() <-chan string {
+	maxLineBytes := s.MaxLineBytes
+	if maxLineBytes <= 0 {
+		maxLineBytes = defaultMaxLineBytes
+	}
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		scanner := bufio.NewScanner(s.R)
+		scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineBytes)
+		for scanner.Scan() {
+			ch <- scanner.Text()
+		}
+		// 読み込みを止めるために R を閉じた場合はエラーにしない
+		if err := scanner.Err(); err != nil && !errors.Is(err, os.ErrClosed) {
+			fmt.Fprintf(os.Stderr, "read input: %v\n", err)
+		}
+	}()
+	return ch
+}
+
+// Close は R が io.Closer なら閉じ、読み込みを止める。
+// 端末の標準入力のように読み込み中の Read を解けない R では、次の行か EOF を読むまで goroutine が残る。
+func (s ReaderSource) Close() error {
+	if c, ok := s.R.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// readSource は src から受け取った行を readLine で扱う。
+// src が終わるか Close されるまで戻らない。Close された後は、src が io.Closer なら閉じ、
+// src の送り手が詰まらないよう、チャネルが閉じるまで残りを読み捨てる。
+func (gm *Game) readSource(src InputSource) {
+	ch := src.Messages()
+	for {
+		select {
+		case line, ok := <-ch:
+			if !ok {
+				return
+			}
+			gm.readLine(line)
+		case <-gm.ctx.Done():
+			if c, ok := src.(io.Closer); ok {
+				c.Close()
+			}
+			go func() {
+				for range ch {
+				}
+			}()
+			return
+		}
+	}
+}
+
+// readLine は入力元から受け取った1行を扱う。clearSentinel と一致する行なら表示中のメッセージを消し、
+// それ以外は空行を除いてメッセージの待ち行列に追加する。
+// clearSentinel の前にバックスラッシュを付けた行は、バックスラッシュを1つ取り除いてメッセージとして表示する。
+func (gm *Game) readLine(line string) {
+	if s := gm.clearSentinel; s != "" && strings.HasSuffix(line, s) {
+		escape := strings.TrimSuffix(line, s)
+		if escape == "" {
+			gm.Clear()
+			return
+		}
+		if strings.Trim(escape, `\`) == "" {
+			line = line[1:]
+		}
+	}
+	if line != "" {
+		gm.enqueue(parseMessage(line))
+	}
+}
+
+// readSources はすべての入力元を並行して読み込み、すべて終わったら done を呼ぶ。done は nil でもよい。
+// Close で止めた場合は done を呼ばない。
+func (gm *Game) readSources(sources []InputSource, done func()) {
+	var wg sync.WaitGroup
+	for _, src := range sources {
+		wg.Go(func() {
+			gm.readSource(src)
+		})
+	}
+	go func() {
+		wg.Wait()
+		if done != nil && gm.ctx.Err() == nil {
+			done()
+		}
+	}()
+}
@@ -0,0 +1,187 @@
+package mascot
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// chanSource は送った文字列をそのまま入力として渡す InputSource。
+type chanSource chan string
+
+func (c chanSource) Messages() <-chan string { return c }
+
+// readAll は gm で sources を読み込み、すべて終わるまで待つ。
+// 入力元は Clear などで Update の中での処理を待つことがあるため、待つ間は予約された処理を実行する。
+// 待ち行列のメッセージを取り出さないよう、Update 自体は呼ばない。
+func readAll(t *testing.T, gm *Game, sources ...InputSource) {
+	t.Helper()
+	done := make(chan struct{})
+	gm.readSources(sources, func() { close(done) })
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case <-done:
+			return
+		case <-deadline:
+			t.Fatal("input sources did not finish")
+		default:
+			gm.runTasks()
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+// queuedTexts は待ち行列のメッセージをすべて取り出し、そのテキストを返す。
+func queuedTexts(gm *Game) []string {
+	var texts []string
+	for {
+		msg, ok := gm.dequeue()
+		if !ok {
+			return texts
+		}
+		texts = append(texts, msg.Text)
+	}
+}
+
+// newSourceTestGame は入力元を持たない Game を作る。
+func newSourceTestGame(t *testing.T) *Game {
+	t.Helper()
+	opts := DefaultOptions()
+	opts.Input = nil
+	return newTestGame(t, opts)
+}
+
+func TestCustomInputSource(t *testing.T) {
+	gm := newSourceTestGame(t)
+	src := make(chanSource)
+	go func() {
+		defer close(src)
+		for _, line := range []string{"hello", "", `{"text":"hi","style":"think"}`} {
+			src <- line
+		}
+	}()
+	readAll(t, gm, src)
+
+	msg, ok := gm.dequeue()
+	if !ok || msg.Text != "hello" {
+		t.Fatalf("first message = %q, %v, want %q", msg.Text, ok, "hello")
+	}
+	// 空行は積まず、JSON の行は指定どおりに解釈する
+	msg, ok = gm.dequeue()
+	if !ok || msg.Text != "hi" || msg.Style != "think" {
+		t.Fatalf("second message = %+v, %v, want text %q and style %q", msg, ok, "hi", "think")
+	}
+	if rest := queuedTexts(gm); len(rest) > 0 {
+		t.Errorf("unexpected queued messages %q", rest)
+	}
+}
+
+func TestReaderSourceLongLine(t *testing.T) {
+	gm := newSourceTestGame(t)
+	// bufio.Scanner の既定の上限 64KiB を超える行も、MaxLineBytes までは1行として読む
+	long := strings.Repeat("a", 100<<10)
+	readAll(t, gm, ReaderSource{R: strings.NewReader(long + "\nnext\n")})
+
+	got := queuedTexts(gm)
+	if len(got) != 2 || got[0] != long || got[1] != "next" {
+		t.Fatalf("queued %d messages, want the %d-byte line and %q", len(got), len(long), "next")
+	}
+}
+
+func TestExitOnEOF(t *testing.T) {
+	opts := DefaultOptions()
+	// 最後の行は改行で終わらない
+	opts.Input = strings.NewReader("first\nlast")
+	opts.ExitOnEOF = true
+	opts.Duration = DisplayDuration{Base: 0.1}
+	gm := newTestGame(t, opts)
+
+	// 入力の終わりを知らせる処理は Update の中で実行されるため、終了するまで Update を呼び続ける
+	var shown []string
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		err := gm.Update()
+		if err == ebiten.Termination {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("did not quit after the input ended; shown %q", shown)
+		}
+		if n := len(shown); gm.hasMessage && (n == 0 || shown[n-1] != gm.messageText) {
+			shown = append(shown, gm.messageText)
+		}
+	}
+	if want := []string{"first", "last"}; !reflect.DeepEqual(shown, want) {
+		t.Errorf("shown messages = %q, want %q", shown, want)
+	}
+}
+
+func TestClearSentinel(t *testing.T) {
+	gm := newSourceTestGame(t)
+	gm.Say("current")
+	updateFrames(t, gm, 1)
+	if !gm.hasMessage {
+		t.Fatal("message is not shown")
+	}
+
+	src := make(chanSource)
+	go func() {
+		defer close(src)
+		src <- defaultClearSentinel
+		src <- `\` + defaultClearSentinel
+		src <- `\\` + defaultClearSentinel
+	}()
+	readAll(t, gm, src)
+
+	if gm.hasMessage {
+		t.Error("the sentinel line did not hide the current message")
+	}
+	// バックスラッシュを付けた行は、1つ取り除いてそのまま表示する
+	want := []string{defaultClearSentinel, `\` + defaultClearSentinel}
+	if got := queuedTexts(gm); !reflect.DeepEqual(got, want) {
+		t.Errorf("queued messages = %q, want %q", got, want)
+	}
+}
+
+func TestReaderSourceClose(t *testing.T) {
+	gm := newSourceTestGame(t)
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	gm.readSources([]InputSource{ReaderSource{R: pr}}, func() { close(done) })
+
+	if _, err := pw.Write([]byte("hello\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := gm.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Close で読み込み側のパイプが閉じられ、書き込みが失敗するようになる。
+	// 閉じられるまでの書き込みは読み捨てられるため、書き込みが詰まることはない
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_, err := pw.Write([]byte("late\n"))
+		if errors.Is(err, io.ErrClosedPipe) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Write() = %v, want %v", err, io.ErrClosedPipe)
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the reader was not closed")
+		}
+	}
+	select {
+	case <-done:
+		t.Error("done was called after Close")
+	default:
+	}
+}
@@ -0,0 +1,133 @@
+package mascot
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+// 表情名
+const (
+	expressionNeutral = "neutral" // 待機中
+	expressionTalking = "talking" // メッセージ表示中
+	expressionBlink   = "blink"   // まばたき中（シートにあれば目を閉じたフレームとして使う）
+)
+
+// defaultExpressions は表情の対応が未指定の場合の表情名とフレーム番号の対応。
+const defaultExpressions = "neutral=0,talking=1,happy=2,surprised=3,sleeping=4"
+
+// spriteSheet は同じサイズの表情フレームをグリッド状に並べた画像。
+type spriteSheet struct {
+	image       *ebiten.Image
+	frameW      int
+	frameH      int
+	expressions map[string]int // 表情名 → フレーム番号（左上から行優先で0始まり）
+}
+
+// loadSpriteSheet は path が指定されていればスプライトシートを読み込む。
+// フレームサイズは size（"幅x高さ"）、表情の対応は mapping（空なら既定の対応）で指定する。
+// 未指定の場合は nil を返す。
+func loadSpriteSheet(path, size, mapping string) (*spriteSheet, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	img, _, err := ebitenutil.NewImageFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load sprite sheet %s: %w", path, err)
+	}
+	w, h, err := parseSize(size)
+	if err != nil {
+		return nil, fmt.Errorf("sprite size: %w", err)
+	}
+	if w > img.Bounds().Dx() || h > img.Bounds().Dy() {
+		return nil, fmt.Errorf("sprite size %dx%d exceeds sheet %s", w, h, path)
+	}
+
+	if mapping == "" {
+		mapping = defaultExpressions
+	}
+	expressions, err := parseExpressions(mapping)
+	if err != nil {
+		return nil, fmt.Errorf("expressions: %w", err)
+	}
+
+	ss := &spriteSheet{image: img, frameW: w, frameH: h, expressions: expressions}
+	for name, index := range expressions {
+		if !ss.rect(index).In(img.Bounds()) {
+			return nil, fmt.Errorf("expression %q: frame %d is outside sheet %s", name, index, path)
+		}
+	}
+	return ss, nil
+}
+
+// rect はフレーム番号に対応するシート上の矩形を返す。
+func (ss *spriteSheet) rect(index int) image.Rectangle {
+	cols := max(ss.image.Bounds().Dx()/ss.frameW, 1)
+	x := (index % cols) * ss.frameW
+	y := (index / cols) * ss.frameH
+	return image.Rect(x, y, x+ss.frameW, y+ss.frameH)
+}
+
+// frame は表情名に対応するフレーム画像を返す。未登録の表情は先頭フレームを返す。
+func (ss *spriteSheet) frame(name string) *ebiten.Image {
+	return ss.image.SubImage(ss.rect(ss.expressions[name])).(*ebiten.Image)
+}
+
+// parseSize は "幅x高さ" 形式の文字列を解析する。
+func parseSize(s string) (int, int, error) {
+	ws, hs, ok := strings.Cut(s, "x")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid size %q", s)
+	}
+	w, err := strconv.Atoi(ws)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse width %q: %w", s, err)
+	}
+	h, err := strconv.Atoi(hs)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse height %q: %w", s, err)
+	}
+	if w <= 0 || h <= 0 {
+		return 0, 0, fmt.Errorf("invalid size %q", s)
+	}
+	return w, h, nil
+}
+
+// parseExpressions は "名前=番号,名前=番号" 形式の表情の対応を解析する。
+func parseExpressions(s string) (map[string]int, error) {
+	expressions := make(map[string]int)
+	for _, pair := range strings.Split(s, ",") {
+		name, is, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid mapping %q", pair)
+		}
+		index, err := strconv.Atoi(is)
+		if err != nil || index < 0 {
+			return nil, fmt.Errorf("invalid frame index %q", pair)
+		}
+		expressions[name] = index
+	}
+	return expressions, nil
+}
+
+// SetExpression はGopherの表情を切り替える。スプライトシートがない場合や未登録の表情名は無視する。
+// 任意のgoroutineから呼び出せる。メインループが終了した後や Close した後は切り替えずに ErrClosed を返す。
+func (gm *Game) SetExpression(name string) error {
+	return gm.runOnUpdate(func() { gm.setExpression(name) })
+}
+
+// setExpression は SetExpression と同じく表情を切り替える。Update の中から呼び出す。
+func (gm *Game) setExpression(name string) {
+	if gm.sprites == nil {
+		return
+	}
+	if _, ok := gm.sprites.expressions[name]; ok {
+		gm.expression = name
+		gm.dirty = true
+	}
+}
@@ -0,0 +1,51 @@
+package mascot
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeSpriteSheet は w×h のフレームを cols 列 rows 行並べた PNG を書き込む。
+func writeSpriteSheet(t *testing.T, w, h, cols, rows int) string {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w*cols, h*rows))
+	path := filepath.Join(t.TempDir(), "sheet.png")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSetExpression(t *testing.T) {
+	opts := DefaultOptions()
+	opts.SpriteSheet = writeSpriteSheet(t, 10, 8, 3, 2)
+	opts.SpriteSize = "10x8"
+	opts.Expressions = "neutral=0,talking=1,happy=4,sleeping=5"
+	gm := newTestGame(t, opts)
+
+	tests := []struct {
+		name  string
+		frame int // 切り替えた後に描画するフレーム
+	}{
+		{"happy", 4},
+		{"sleeping", 5},
+		{"unknown", 5}, // 未登録の表情は無視する
+		{"neutral", 0},
+	}
+	for _, tt := range tests {
+		gm.setExpression(tt.name)
+		img := gm.currentGopherImage()
+		want := image.Rect(10*(tt.frame%3), 8*(tt.frame/3), 10*(tt.frame%3)+10, 8*(tt.frame/3)+8)
+		if got := img.Bounds(); got != want {
+			t.Errorf("setExpression(%q): bounds = %v, want %v", tt.name, got, want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package mascot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// windowState は再起動後も引き継ぐウィンドウの状態。
+// ウィンドウはメッセージに応じて配置した角を固定したままリサイズするため、四辺の座標を保存し、
+// 復元時は固定する角に対応する辺を使う。
+type windowState struct {
+	Left   int `json:"left"`
+	Top    int `json:"top"`
+	Right  int `json:"right"`
+	Bottom int `json:"bottom"`
+
+	// 吹き出しを既定の位置からずらした量
+	BubbleOffsetX float32 `json:"bubbleOffsetX,omitempty"`
+	BubbleOffsetY float32 `json:"bubbleOffsetY,omitempty"`
+}
+
+// stateFilePath はウィンドウ状態を保存するファイルのパスを返す。
+func stateFilePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("user config dir: %w", err)
+	}
+	return filepath.Join(dir, "sample-go-ebiten", "state.json"), nil
+}
+
+// loadWindowState は保存されたウィンドウ状態を読み込む。初回起動などでファイルがない場合は false を返す。
+func loadWindowState() (windowState, bool, error) {
+	path, err := stateFilePath()
+	if err != nil {
+		return windowState{}, false, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return windowState{}, false, nil
+	}
+	if err != nil {
+		return windowState{}, false, fmt.Errorf("read state: %w", err)
+	}
+	var st windowState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return windowState{}, false, fmt.Errorf("decode state %s: %w", path, err)
+	}
+	return st, true, nil
+}
+
+// saveWindowState はウィンドウ状態をファイルに保存する。
+func saveWindowState(st windowState) error {
+	path, err := stateFilePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create state dir: %w", err)
+	}
+	data, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("encode state: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+	return nil
+}
+
+// windowState は現在のウィンドウ状態を返す。
+func (gm *Game) windowState() windowState {
+	return windowState{
+		Left:   gm.windowX,
+		Top:    gm.windowY,
+		Right:  gm.windowX + gm.screenWidth,
+		Bottom: gm.windowY + gm.screenHeight,
+
+		BubbleOffsetX: gm.bubbleOffX,
+		BubbleOffsetY: gm.bubbleOffY,
+	}
+}
+
+// saveState は現在のウィンドウ位置を保存する。保存に失敗しても動作は継続する。
+func (gm *Game) saveState() {
+	if err := saveWindowState(gm.windowState()); err != nil {
+		fmt.Fprintf(os.Stderr, "save window state: %v\n", err)
+	}
+}
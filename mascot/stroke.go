@@ -0,0 +1,74 @@
+package mascot
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// strokeMiterLimit は角の形に miter を指定した場合に、尖らせる角の鋭さの上限。
+const strokeMiterLimit = 10
+
+// strokeStyle は吹き出しの枠線の端と角の形。指定のない項目は、描く部分ごとの既定の形を使う。
+type strokeStyle struct {
+	cap     vector.LineCap
+	join    vector.LineJoin
+	capSet  bool
+	joinSet bool
+}
+
+// parseStrokeStyle は端の形（"butt"・"round"・"square"）と角の形（"miter"・"round"・"bevel"）の名前を解析する。
+// 空の名前は指定なしとして扱う。
+func parseStrokeStyle(capName, joinName string) (strokeStyle, error) {
+	var st strokeStyle
+	switch capName {
+	case "":
+	case "butt":
+		st.cap, st.capSet = vector.LineCapButt, true
+	case "round":
+		st.cap, st.capSet = vector.LineCapRound, true
+	case "square":
+		st.cap, st.capSet = vector.LineCapSquare, true
+	default:
+		return st, fmt.Errorf("unknown line cap %q", capName)
+	}
+	switch joinName {
+	case "":
+	case "miter":
+		st.join, st.joinSet = vector.LineJoinMiter, true
+	case "round":
+		st.join, st.joinSet = vector.LineJoinRound, true
+	case "bevel":
+		st.join, st.joinSet = vector.LineJoinBevel, true
+	default:
+		return st, fmt.Errorf("unknown line join %q", joinName)
+	}
+	return st, nil
+}
+
+// options は太さ width の枠線の描画オプションを返す。端と角の形は、指定がなければ defCap・defJoin にする。
+func (st strokeStyle) options(width float32, defCap vector.LineCap, defJoin vector.LineJoin) *vector.StrokeOptions {
+	op := &vector.StrokeOptions{Width: width, LineCap: defCap, LineJoin: defJoin}
+	if st.capSet {
+		op.LineCap = st.cap
+	}
+	if st.joinSet {
+		op.LineJoin = st.join
+		// MiterLimit が0のままだと尖った角にならないため、指定された場合は一般的な値にする
+		op.MiterLimit = strokeMiterLimit
+	}
+	return op
+}
+
+// strokeInset は枠線を吹き出しの内側に寄せる量(px)を返す。
+// 枠線は吹き出しの輪郭を中心に描くため、太くすると外側にもはみ出し、ウィンドウの端や角丸で欠ける。
+// 既定の太さより太い分の半分だけ輪郭を内側に寄せ、枠線の外側の縁を既定の太さと同じ位置に保つ。
+func strokeInset(width float64) float32 {
+	return float32(max(width-strokeWidth, 0) / 2)
+}
+
+// bubblePath は (x, y) から幅 w・高さ h、角丸の半径 r の吹き出しの輪郭を、枠線の太さに合わせて内側に寄せたパスを返す。
+func (gm *Game) bubblePath(x, y, w, h, r float32) *vector.Path {
+	in := strokeInset(gm.layoutCfg.StrokeWidth)
+	return roundRectPath(x+in, y+in, max(w-in*2, 0), max(h-in*2, 0), max(r-in, 0))
+}
@@ -0,0 +1,113 @@
+package mascot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// テーマのディレクトリに置くファイルの名前。
+const (
+	themeImageFile  = "gopher.png"
+	themeFontFile   = "font.ttf"
+	themeConfigFile = "theme.json"
+)
+
+// themeConfig はテーマの theme.json の内容。指定のない項目は元の設定のままにする。
+type themeConfig struct {
+	BubbleFill   string   `json:"bubbleFill,omitempty"`   // 吹き出しの塗りつぶし色（"#rrggbb" または "#rrggbbaa"）
+	BubbleStroke string   `json:"bubbleStroke,omitempty"` // 吹き出しの枠線の色
+	Background   string   `json:"background,omitempty"`   // ウィンドウの背景色
+	BubbleRadius *float64 `json:"bubbleRadius,omitempty"` // 吹き出しの角丸の半径(px)
+	BubblePadX   *float64 `json:"bubblePadX,omitempty"`   // 吹き出しの左右の余白の合計(px)
+	BubblePadY   *float64 `json:"bubblePadY,omitempty"`   // 吹き出しの上下の余白の合計(px)
+	StrokeWidth  *float64 `json:"strokeWidth,omitempty"`  // 吹き出しの枠線の太さ(px)
+	FontSize     *int     `json:"fontSize,omitempty"`     // 文字サイズ(px)
+}
+
+// applyTheme はテーマのディレクトリ dir にある Gopher画像・フォント・theme.json で設定を上書きする。
+// ないファイルの分は元の設定（未指定なら埋め込みの画像・フォント）のまま使う。
+// theme.json が壊れている場合は標準エラーに知らせ、色や大きさは元の設定のままにする。
+func (opts *Options) applyTheme(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("stat theme dir: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("theme %s is not a directory", dir)
+	}
+	if p := filepath.Join(dir, themeImageFile); fileExists(p) {
+		opts.Image = p
+	}
+	if p := filepath.Join(dir, themeFontFile); fileExists(p) {
+		opts.Font = p
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, themeConfigFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read theme config: %w", err)
+	}
+	var cfg themeConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "parse theme config: %v; using the default colors and sizes\n", err)
+		return nil
+	}
+	if err := opts.applyThemeConfig(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "%v; using the default colors and sizes\n", err)
+	}
+	return nil
+}
+
+// applyThemeConfig は theme.json で指定された項目で設定を上書きする。
+// 色の値が不正ならエラーを返し、設定は一つも変えない。
+func (opts *Options) applyThemeConfig(cfg themeConfig) error {
+	o := *opts
+	for _, c := range []struct {
+		v   string
+		dst *color.Color
+	}{
+		{cfg.BubbleFill, &o.BubbleFill},
+		{cfg.BubbleStroke, &o.BubbleStroke},
+		{cfg.Background, &o.Background},
+	} {
+		if c.v == "" {
+			continue
+		}
+		clr, err := parseHexColor(c.v)
+		if err != nil {
+			return fmt.Errorf("parse theme color: %w", err)
+		}
+		*c.dst = clr
+	}
+	for _, f := range []struct {
+		v   *float64
+		dst *float64
+	}{
+		{cfg.BubbleRadius, &o.BubbleRadius},
+		{cfg.BubblePadX, &o.BubblePadX},
+		{cfg.BubblePadY, &o.BubblePadY},
+		{cfg.StrokeWidth, &o.StrokeWidth},
+	} {
+		if f.v != nil {
+			*f.dst = *f.v
+		}
+	}
+	if cfg.FontSize != nil {
+		o.FontSize = *cfg.FontSize
+	}
+	*opts = o
+	return nil
+}
+
+// fileExists は path に通常のファイルがあるかどうかを返す。
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
@@ -0,0 +1,110 @@
+package mascot
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeThemeFile はテーマのディレクトリ dir に name のファイルを書き込む。
+func writeThemeFile(t *testing.T, dir, name string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeThemeImage はテーマのディレクトリ dir に w×h の PNG画像を gopher.png として書き込む。
+func writeThemeImage(t *testing.T, dir string, w, h int) {
+	t.Helper()
+	f, err := os.Create(filepath.Join(dir, themeImageFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// sameColor は a と b が同じ色かどうかを返す。
+func sameColor(a, b color.Color) bool {
+	return color.RGBAModel.Convert(a) == color.RGBAModel.Convert(b)
+}
+
+func TestApplyTheme(t *testing.T) {
+	dir := t.TempDir()
+	writeThemeImage(t, dir, 12, 10)
+	writeThemeFile(t, dir, themeConfigFile, []byte(`{"bubbleFill":"#ff0000","bubbleStroke":"#00ff0080","bubbleRadius":4,"fontSize":30}`))
+
+	opts := DefaultOptions()
+	if err := opts.applyTheme(dir); err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, themeImageFile); opts.Image != want {
+		t.Errorf("Image = %q, want %q", opts.Image, want)
+	}
+	if want := (color.RGBA{0xff, 0x00, 0x00, 0xff}); !sameColor(opts.BubbleFill, want) {
+		t.Errorf("BubbleFill = %v, want %v", opts.BubbleFill, want)
+	}
+	if want := (color.NRGBA{0x00, 0xff, 0x00, 0x80}); !sameColor(opts.BubbleStroke, want) {
+		t.Errorf("BubbleStroke = %v, want %v", opts.BubbleStroke, want)
+	}
+	if opts.BubbleRadius != 4 {
+		t.Errorf("BubbleRadius = %v, want 4", opts.BubbleRadius)
+	}
+	if opts.FontSize != 30 {
+		t.Errorf("FontSize = %d, want 30", opts.FontSize)
+	}
+	def := DefaultOptions()
+	if opts.BubblePadX != def.BubblePadX || !sameColor(opts.Background, def.Background) {
+		t.Errorf("unspecified settings changed: BubblePadX = %v, Background = %v", opts.BubblePadX, opts.Background)
+	}
+
+	gm := newTestGame(t, opts)
+	if got, want := gm.gopherImage.Bounds().Size(), image.Pt(12, 10); got != want {
+		t.Errorf("gopher image size = %v, want %v", got, want)
+	}
+	if !sameColor(gm.bubbleFill, color.RGBA{0xff, 0x00, 0x00, 0xff}) {
+		t.Errorf("bubbleFill = %v, want red", gm.bubbleFill)
+	}
+}
+
+func TestApplyThemeBroken(t *testing.T) {
+	defaultSize := newTestGame(t, DefaultOptions()).gopherImage.Bounds().Size()
+
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{"invalid json", `{"bubbleFill": "#ff0000",`},
+		{"invalid color", `{"bubbleRadius": 4, "bubbleFill": "#zzzzzz"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeThemeFile(t, dir, themeImageFile, []byte("not a png"))
+			writeThemeFile(t, dir, themeConfigFile, []byte(tt.config))
+
+			opts := DefaultOptions()
+			if err := opts.applyTheme(dir); err != nil {
+				t.Fatalf("applyTheme: %v", err)
+			}
+			def := DefaultOptions()
+			if opts.BubbleRadius != def.BubbleRadius {
+				t.Errorf("BubbleRadius = %v, want default %v", opts.BubbleRadius, def.BubbleRadius)
+			}
+			if !sameColor(opts.BubbleFill, def.BubbleFill) {
+				t.Errorf("BubbleFill = %v, want default %v", opts.BubbleFill, def.BubbleFill)
+			}
+
+			gm := newTestGame(t, opts)
+			if got := gm.gopherImage.Bounds().Size(); got != defaultSize {
+				t.Errorf("gopher image size = %v, want default %v", got, defaultSize)
+			}
+		})
+	}
+}
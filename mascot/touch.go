@@ -0,0 +1,39 @@
+package mascot
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// touchPosition は指の位置を論理座標で返す。
+func (gm *Game) touchPosition(id ebiten.TouchID) (int, int) {
+	x, y := ebiten.TouchPosition(id)
+	return int(float64(x) / gm.deviceScale), int(float64(y) / gm.deviceScale)
+}
+
+// updateTouchDrag は指でのGopherのドラッグを処理する。Gopherに最初に触れた指だけを追い、
+// その指を離すまで true を返す。マウスのボタンを押している間やドラッグ中は新しいタッチを無視する。
+func (gm *Game) updateTouchDrag() bool {
+	if gm.touching {
+		if inpututil.IsTouchJustReleased(gm.touchID) {
+			gm.touching = false
+			gm.endDrag()
+			return false
+		}
+		gm.moveDrag(gm.touchPosition(gm.touchID))
+		return true
+	}
+	if gm.clickThrough || gm.dragging || ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		return false
+	}
+	for _, id := range inpututil.AppendJustPressedTouchIDs(nil) {
+		x, y := gm.touchPosition(id)
+		if gm.hitGopher(x, y) {
+			gm.touchID = id
+			gm.touching = true
+			gm.startDrag(x, y)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+//go:build tray && !darwin
+
+package mascot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/png"
+
+	"fyne.io/systray"
+	"golang.org/x/image/draw"
+)
+
+// trayIconSize はトレイアイコンの一辺(px)。
+const trayIconSize = 64
+
+// trayState はトレイのメニューのチェックに映す Game の状態。
+type trayState struct {
+	floating bool
+	muted    bool
+}
+
+// startTray はシステムトレイにアイコンを置き、メッセージの消去・終了・最前面表示・ミュートのメニューを表示する。
+// トレイは専用のgoroutineでイベントループを動かし、メニューの操作は runOnUpdate で Update の中で反映する。
+// トレイのgoroutineは Game の状態を直接読まず、起動時に写し取った状態と、キー操作を含めて切り替えるたびに
+// onToggle から送られる状態でチェックを更新する。Close でトレイを取り除く。
+func (gm *Game) startTray() error {
+	icon, err := trayIcon(gopherPNG)
+	if err != nil {
+		return err
+	}
+	initial := trayState{floating: gm.floating, muted: gm.muted}
+	states := make(chan trayState, 1)
+	gm.onToggle = func() {
+		// 送るのは Update のgoroutineだけなので、未読の古い状態を捨ててから送れば詰まらない
+		select {
+		case <-states:
+		default:
+		}
+		states <- trayState{floating: gm.floating, muted: gm.muted}
+	}
+	gm.closers = append(gm.closers, func() error {
+		systray.Quit()
+		return nil
+	})
+	go systray.Run(func() {
+		systray.SetIcon(icon)
+		systray.SetTooltip("Gopher")
+		clearItem := systray.AddMenuItem("Clear message", "Clear the current message")
+		floating := systray.AddMenuItemCheckbox("Always on top", "Keep the window above other windows", initial.floating)
+		mute := systray.AddMenuItemCheckbox("Mute", "Do not play the notification sound", initial.muted)
+		systray.AddSeparator()
+		quit := systray.AddMenuItem("Quit", "Quit the app")
+		for {
+			select {
+			case <-clearItem.ClickedCh:
+				gm.Clear()
+			case <-floating.ClickedCh:
+				gm.runOnUpdate(gm.toggleFloating)
+			case <-mute.ClickedCh:
+				gm.runOnUpdate(gm.toggleMute)
+			case s := <-states:
+				setChecked(floating, s.floating)
+				setChecked(mute, s.muted)
+			case <-quit.ClickedCh:
+				gm.Quit()
+				return
+			case <-gm.ctx.Done():
+				return
+			}
+		}
+	}, nil)
+	return nil
+}
+
+// setChecked はメニュー項目のチェックを on に合わせる。
+func setChecked(item *systray.MenuItem, on bool) {
+	if on {
+		item.Check()
+	} else {
+		item.Uncheck()
+	}
+}
+
+// trayIcon は画像を trayIconSize 四方に縦横比を保って縮小し、PNG を格納した ICO 形式にする。
+// Windows は ICO 形式だけを受け付け、他の環境も ICO 形式を受け付ける。
+func trayIcon(data []byte) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode tray icon: %w", err)
+	}
+	b := src.Bounds()
+	scale := min(float64(trayIconSize)/float64(b.Dx()), float64(trayIconSize)/float64(b.Dy()))
+	w, h := int(float64(b.Dx())*scale), int(float64(b.Dy())*scale)
+	x, y := (trayIconSize-w)/2, (trayIconSize-h)/2
+	dst := image.NewNRGBA(image.Rect(0, 0, trayIconSize, trayIconSize))
+	draw.CatmullRom.Scale(dst, image.Rect(x, y, x+w, y+h), src, b, draw.Over, nil)
+
+	var pngData bytes.Buffer
+	if err := png.Encode(&pngData, dst); err != nil {
+		return nil, fmt.Errorf("encode tray icon: %w", err)
+	}
+
+	// ICONDIR（6バイト）と ICONDIRENTRY（16バイト）に続けて PNG を置く
+	var ico bytes.Buffer
+	le := binary.LittleEndian
+	ico.Write(le.AppendUint16(nil, 0)) // 予約
+	ico.Write(le.AppendUint16(nil, 1)) // 種類（アイコン）
+	ico.Write(le.AppendUint16(nil, 1)) // 画像の数
+	ico.Write([]byte{trayIconSize, trayIconSize, 0, 0})
+	ico.Write(le.AppendUint16(nil, 1))  // プレーン数
+	ico.Write(le.AppendUint16(nil, 32)) // 色のビット数
+	ico.Write(le.AppendUint32(nil, uint32(pngData.Len())))
+	ico.Write(le.AppendUint32(nil, 6+16))
+	ico.Write(pngData.Bytes())
+	return ico.Bytes(), nil
+}
@@ -0,0 +1,12 @@
+//go:build !tray || darwin
+
+package mascot
+
+import "errors"
+
+// startTray はトレイに対応していないビルドではエラーを返す。
+// トレイを使うには Windows か Linux で -tags tray を付けてビルドする。macOS では Ebiten とトレイの両方が
+// メインスレッドを必要とするため対応しない。
+func (gm *Game) startTray() error {
+	return errors.New("tray icon is not available in this build; build with -tags tray on Windows or Linux")
+}
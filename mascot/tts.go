@@ -0,0 +1,80 @@
+package mascot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// speaker はメッセージをOSの音声合成コマンドで読み上げる。
+// テキストは引数ではなく標準入力で渡し、読み上げ中に次のメッセージが来たら前の読み上げを止める。
+type speaker struct {
+	name string   // 音声合成コマンド
+	args []string // コマンドの引数
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// newSpeaker はプラットフォームの音声合成コマンドを使う speaker を返す。
+//   - macOS: say -f -
+//   - Linux: espeak --stdin、なければ spd-say -e -w
+//   - Windows: PowerShell の System.Speech
+func newSpeaker() (*speaker, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return &speaker{name: "say", args: []string{"-f", "-"}}, nil
+	case "windows":
+		return &speaker{name: "powershell", args: []string{
+			"-NoProfile", "-NonInteractive", "-Command",
+			"Add-Type -AssemblyName System.Speech; (New-Object System.Speech.Synthesis.SpeechSynthesizer).Speak([Console]::In.ReadToEnd())",
+		}}, nil
+	}
+	for _, c := range []struct {
+		name string
+		args []string
+	}{
+		{name: "espeak", args: []string{"--stdin"}},
+		{name: "spd-say", args: []string{"-e", "-w"}},
+	} {
+		if _, err := exec.LookPath(c.name); err == nil {
+			return &speaker{name: c.name, args: c.args}, nil
+		}
+	}
+	return nil, errors.New("no text-to-speech command found (install espeak or speech-dispatcher)")
+}
+
+// speak は text の読み上げを始める。読み上げ中のテキストがあれば止める。
+func (s *speaker) speak(text string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		cmd := exec.CommandContext(ctx, s.name, s.args...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "speak: %v\n", err)
+		}
+	}()
+}
+
+// stop は読み上げ中のテキストがあれば止める。
+func (s *speaker) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+}
@@ -0,0 +1,344 @@
+package mascot
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"unicode"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+var (
+	testFaceOnce sync.Once
+	testFaceVal  text.Face
+	testFaceErr  error
+)
+
+// testFace は埋め込みのフォントから既定の文字サイズのフェイスを作る。フォントの読み込みはテスト全体で1回だけ行う。
+func testFace(tb testing.TB) text.Face {
+	tb.Helper()
+	testFaceOnce.Do(func() {
+		fonts, err := loadFonts("", nil)
+		if err != nil {
+			testFaceErr = err
+			return
+		}
+		testFaceVal, testFaceErr = newFontFaces(fonts, defaultFontSize, 1)
+	})
+	if testFaceErr != nil {
+		tb.Fatal(testFaceErr)
+	}
+	return testFaceVal
+}
+
+func TestWrapText(t *testing.T) {
+	face := testFace(t)
+	tests := []struct {
+		name     string
+		msg      string
+		maxWidth float64
+		want     string
+	}{
+		{
+			name:     "fits on one line",
+			msg:      "hello",
+			maxWidth: 1000,
+			want:     "hello",
+		},
+		{
+			name:     "keeps newlines",
+			msg:      "a\n\nb",
+			maxWidth: 1000,
+			want:     "a\n\nb",
+		},
+		{
+			name:     "overlong word is broken per rune",
+			msg:      "configuration",
+			maxWidth: measureText(face, "configura"),
+			want:     "configura\ntion",
+		},
+		{
+			name:     "japanese is broken per rune",
+			msg:      "こんにちは世界",
+			maxWidth: measureText(face, "こんにち"),
+			want:     "こんにち\nは世界",
+		},
+		{
+			name:     "no leading space after a wrap",
+			msg:      "aa aa",
+			maxWidth: measureText(face, "aa"),
+			want:     "aa\naa",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapText(tt.msg, face, tt.maxWidth, false); got != tt.want {
+				t.Errorf("wrapText(%q, %v) = %q, want %q", tt.msg, tt.maxWidth, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrapTextKeepsWords(t *testing.T) {
+	face := testFace(t)
+	const msg = "the quick brown fox jumps"
+	words := map[string]bool{}
+	var longest float64
+	for _, w := range strings.Fields(msg) {
+		words[w] = true
+		longest = max(longest, measureText(face, w))
+	}
+	// 最も長い単語がちょうど収まる幅から広げていき、どの幅でも単語の途中で改行しないことを確かめる
+	for maxWidth := longest; maxWidth <= measureText(face, msg); maxWidth += 5 {
+		wrapped := wrapText(msg, face, maxWidth, false)
+		for _, line := range strings.Split(wrapped, "\n") {
+			if line == "" || unicode.IsSpace([]rune(line)[0]) {
+				t.Errorf("width %v: line %q is empty or starts with a space", maxWidth, line)
+			}
+			for _, w := range strings.Fields(line) {
+				if !words[w] {
+					t.Errorf("width %v: word split into %q in %q", maxWidth, w, wrapped)
+				}
+			}
+			if w := measureText(face, line); w > maxWidth {
+				t.Errorf("width %v: line %q is %v wide", maxWidth, line, w)
+			}
+		}
+	}
+}
+
+// wrapTextMeasured は wrapText と同じ規則で、単語を加えるたびに候補の行全体を measureText で計測し直して折り返す。
+// 幅を積み上げて計算する wrapText の結果が、これと一致することを確かめるために使う。
+func wrapTextMeasured(msg string, face text.Face, maxWidth float64, cjk bool) string {
+	var result []string
+	for _, para := range strings.Split(msg, "\n") {
+		if para == "" {
+			result = append(result, "")
+			continue
+		}
+		var line []rune
+		for _, word := range wrapUnits(para, cjk) {
+			hang := cjk && len(word) == 1 && noLineStart(word[0])
+			if len(line) > 0 && !hang && measureText(face, string(line)+string(word)) > maxWidth {
+				result = append(result, string(line))
+				line = nil
+				if len(word) == 1 && unicode.IsSpace(word[0]) {
+					continue
+				}
+			}
+			if len(word) == 1 || measureText(face, string(word)) <= maxWidth {
+				line = append(line, word...)
+				continue
+			}
+			for _, c := range splitClusters(word) {
+				if len(line) > 0 && measureText(face, string(line)+string(c)) > maxWidth {
+					result = append(result, string(line))
+					line = nil
+				}
+				line = append(line, c...)
+			}
+		}
+		if len(line) > 0 {
+			result = append(result, string(line))
+		}
+	}
+	return strings.Join(result, "\n")
+}
+
+func FuzzWrapText(f *testing.F) {
+	f.Add("the quick brown fox jumps over the lazy dog", uint16(120), false)
+	f.Add("AVATAR WAVE Type To LT", uint16(60), false)
+	f.Add("configuration management", uint16(40), false)
+	f.Add("こんにちは、世界。今日はいい天気ですね！", uint16(100), true)
+	f.Add("Goは楽しい。Gopherくん、こんにちは。", uint16(90), true)
+	f.Add("été 🇯🇵🇺🇸 👨‍👩‍👧 ok", uint16(50), false)
+	f.Add("line one\n\nline\ttwo  with  spaces", uint16(80), false)
+	face := testFace(f)
+	f.Fuzz(func(t *testing.T, msg string, width uint16, cjk bool) {
+		// showMessage と同じく、改行として扱われる文字はそろえてから折り返す
+		msg = lineBreaks.Replace(msg)
+		maxWidth := float64(width%500) + 10
+		got := wrapText(msg, face, maxWidth, cjk)
+		want := wrapTextMeasured(msg, face, maxWidth, cjk)
+		if got != want {
+			t.Errorf("wrapText(%q, %v, %v) = %q, want %q", msg, maxWidth, cjk, got, want)
+		}
+	})
+}
+
+func BenchmarkWrapText(b *testing.B) {
+	face := testFace(b)
+	for _, bm := range []struct {
+		name string
+		msg  string
+	}{
+		{"latin", strings.Repeat("The quick brown fox jumps over the lazy dog. ", 40)},
+		{"japanese", strings.Repeat("吾輩は猫である。名前はまだ無い。", 40)},
+		{"long_word", strings.Repeat("a", 2000)},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			for b.Loop() {
+				wrapText(bm.msg, face, defaultMaxLineWidth, false)
+			}
+		})
+		b.Run(bm.name+"_measured", func(b *testing.B) {
+			for b.Loop() {
+				wrapTextMeasured(bm.msg, face, defaultMaxLineWidth, false)
+			}
+		})
+	}
+}
+
+func TestWrapTextCJK(t *testing.T) {
+	face := testFace(t)
+	tests := []struct {
+		name     string
+		msg      string
+		maxWidth float64
+		cjk      bool
+		want     string
+	}{
+		{
+			name:     "ascii in japanese fills the line",
+			msg:      "これはconfigurationです",
+			maxWidth: measureText(face, "これはconfig"),
+			cjk:      true,
+			want:     "これはconfig\nurationです",
+		},
+		{
+			name:     "ascii in latin mode moves to the next line",
+			msg:      "これはconfigurationです",
+			maxWidth: measureText(face, "configuration"),
+			want:     "これは\nconfiguration\nです",
+		},
+		{
+			name:     "punctuation hangs at the line end",
+			msg:      "こんにちは。世界",
+			maxWidth: measureText(face, "こんにちは"),
+			cjk:      true,
+			want:     "こんにちは。\n世界",
+		},
+		{
+			name:     "punctuation after ascii hangs",
+			msg:      "テストはOK。次へ",
+			maxWidth: measureText(face, "テストはOK"),
+			cjk:      true,
+			want:     "テストはOK。\n次へ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapText(tt.msg, face, tt.maxWidth, tt.cjk); got != tt.want {
+				t.Errorf("wrapText(%q, %v, %v) = %q, want %q", tt.msg, tt.maxWidth, tt.cjk, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitLines(t *testing.T) {
+	face := testFace(t)
+	lines := make([]string, 100)
+	for i := range lines {
+		lines[i] = strings.Repeat("gopher ", 20)
+	}
+	wrapped := wrapText(strings.Join(lines, "\n"), face, defaultMaxLineWidth, false)
+	for _, maxLines := range []int{1, 3, 10} {
+		got := strings.Split(limitLines(wrapped, face, maxLines, defaultMaxLineWidth), "\n")
+		if len(got) != maxLines {
+			t.Fatalf("maxLines %d: got %d lines", maxLines, len(got))
+		}
+		last := got[maxLines-1]
+		if !strings.HasSuffix(last, ellipsis) {
+			t.Errorf("maxLines %d: last line %q does not end with %q", maxLines, last, ellipsis)
+		}
+		if w := measureText(face, last); w > defaultMaxLineWidth {
+			t.Errorf("maxLines %d: last line %q is %v wide, want at most %v", maxLines, last, w, float64(defaultMaxLineWidth))
+		}
+	}
+}
+
+func TestWrapTextTabs(t *testing.T) {
+	face := testFace(t)
+	s := expandTabs("a\tb", 4)
+	w := measureText(face, s)
+	if ab := measureText(face, "ab"); w <= ab {
+		t.Errorf("expanded width %v, want wider than %q (%v)", w, "ab", ab)
+	}
+
+	// 展開した幅に収まれば1行のまま、足りなければタブの後で折り返す
+	if got := wrapText(s, face, w, false); got != s {
+		t.Errorf("wrapText(%q) in %v px = %q, want it unchanged", s, w, got)
+	}
+	got := strings.Split(wrapText(s, face, w-1, false), "\n")
+	if len(got) != 2 || strings.TrimRight(got[0], " ") != "a" || got[1] != "b" {
+		t.Errorf("wrapText(%q) in %v px = %q, want [\"a   \" \"b\"]", s, w-1, got)
+	}
+
+	// 行頭のインデントは残す
+	if got := wrapText(expandTabs("\tb", 4), face, defaultMaxLineWidth, false); got != "    b" {
+		t.Errorf("wrapText of an indented line = %q, want %q", got, "    b")
+	}
+}
+
+func TestMaxWidth(t *testing.T) {
+	msg := strings.Repeat("the quick brown fox jumps over the lazy gopher ", 4)
+	prev := 0
+	for _, maxWidth := range []int{600, 300, 150} {
+		opts := DefaultOptions()
+		opts.MaxWidth = maxWidth
+		gm := newTestGame(t, opts)
+		gm.showMessage(parseMessage(msg))
+		lines := gm.layout.lines
+		for _, line := range lines {
+			if w := measureText(gm.fontFace, line); w > float64(maxWidth) {
+				t.Errorf("max width %d: line %q is %v wide", maxWidth, line, w)
+			}
+		}
+		// 幅を狭めるほど行が増える
+		if len(lines) <= prev {
+			t.Errorf("max width %d: %d lines, want more than %d at a wider width", maxWidth, len(lines), prev)
+		}
+		prev = len(lines)
+	}
+}
+
+func TestMeasureTextMatchesDrawn(t *testing.T) {
+	face := testFace(t)
+	// 右端のインクの列を返す。何も描かれていなければ -1
+	rightmost := func(draw func(dst *ebiten.Image)) int {
+		img := ebiten.NewImage(1000, 100)
+		defer img.Deallocate()
+		draw(img)
+		got := readImage(img)
+		for x := got.Bounds().Dx() - 1; x >= 0; x-- {
+			for y := range got.Bounds().Dy() {
+				if got.RGBAAt(x, y).A > 0x80 {
+					return x
+				}
+			}
+		}
+		return -1
+	}
+	drawAt := func(dst *ebiten.Image, s string, x float64) {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(x, 0)
+		text.Draw(dst, s, face, op)
+	}
+
+	// 続けて描いた縦棒の位置が、計測した幅の位置に描いた縦棒と一致すれば、計測と描画の送り幅は同じ。
+	// サブピクセルの位置の丸めの違いで 1px ずれることは許す
+	for _, s := range []string{"Hello, Gopher!", "WAVE AVAWA", "ffi fl", "1.0 + 1.1", "こんにちは、世界"} {
+		t.Run(s, func(t *testing.T) {
+			drawn := rightmost(func(dst *ebiten.Image) { drawAt(dst, s+"|", 0) })
+			measured := rightmost(func(dst *ebiten.Image) {
+				drawAt(dst, s, 0)
+				drawAt(dst, "|", measureText(face, s))
+			})
+			if drawn < 0 || drawn-measured > 1 || measured-drawn > 1 {
+				t.Errorf("bar drawn after the text at x = %d, at the measured width at x = %d", drawn, measured)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package mascot
+
+import (
+	"container/list"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// wrapCacheSize は折り返し結果を覚えておくメッセージの数。
+const wrapCacheSize = 32
+
+// wrapKey は折り返し結果を決める入力。同じキーなら折り返しとスタイルの対応付けは同じになる。
+type wrapKey struct {
+	text     string // 記法を解釈する前のメッセージ
+	width    float64
+	maxLines int
+	face     text.Face
+}
+
+// wrapResult はメッセージを折り返した結果。
+type wrapResult struct {
+	wrapped string
+	styles  [][]textStyle // 折り返し後の各行の文字のスタイル
+}
+
+// wrapCache は最近使った折り返し結果を wrapCacheSize 件まで覚えておく LRU キャッシュ。
+// スクリプトが同じ行を繰り返し送る場合に、1文字ごとに幅を計測する折り返しをやり直さずに済む。
+// Update の中からのみ使う。
+type wrapCache struct {
+	order   *list.List // 使った順（先頭が最新）。要素の値は wrapKey
+	entries map[wrapKey]*list.Element
+	results map[wrapKey]wrapResult
+}
+
+func newWrapCache() *wrapCache {
+	return &wrapCache{
+		order:   list.New(),
+		entries: map[wrapKey]*list.Element{},
+		results: map[wrapKey]wrapResult{},
+	}
+}
+
+// get は key の折り返し結果を返し、最近使ったものとして記録する。
+func (c *wrapCache) get(key wrapKey) (wrapResult, bool) {
+	e, ok := c.entries[key]
+	if !ok {
+		return wrapResult{}, false
+	}
+	c.order.MoveToFront(e)
+	return c.results[key], true
+}
+
+// put は key の折り返し結果を記録し、件数を超えた場合は最も長く使っていないものを捨てる。
+func (c *wrapCache) put(key wrapKey, r wrapResult) {
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		c.results[key] = r
+		return
+	}
+	c.entries[key] = c.order.PushFront(key)
+	c.results[key] = r
+	if c.order.Len() > wrapCacheSize {
+		oldest := c.order.Back()
+		k := c.order.Remove(oldest).(wrapKey)
+		delete(c.entries, k)
+		delete(c.results, k)
+	}
+}
+
+// wrapCached は記法を解釈する前のメッセージ src を、表示中の幅・行数・フォントで折り返した結果を返す。
+// plain と styles は src の記法を解釈した結果で、sc はその文字体系。
+// 同じ条件で折り返した結果が wrapCache にあれば、折り返しをやり直さずにそれを返す。
+func (gm *Game) wrapCached(src, plain string, styles []textStyle, sc script) wrapResult {
+	key := wrapKey{text: src, width: gm.maxLineWidth, maxLines: gm.maxLines, face: gm.fontFace}
+	if wr, ok := gm.wrapCache.get(key); ok {
+		return wr
+	}
+	var wr wrapResult
+	wr.wrapped = limitLines(gm.wrapMessage(plain, styles, sc, gm.maxLineWidth), gm.fontFace, gm.maxLines, gm.maxLineWidth)
+	wr.styles = splitStyles(plain, styles, strings.Split(wr.wrapped, "\n"))
+	gm.wrapCache.put(key, wr)
+	return wr
+}
@@ -0,0 +1,68 @@
+package mascot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWrapCachedInvalidation(t *testing.T) {
+	gm := newTestGame(t, DefaultOptions())
+	src := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 4)
+	plain, styles := parseMarkup(parseANSIColors(src))
+	sc := detectScript(plain)
+	wrap := func() string { return gm.wrapCached(src, plain, styles, sc).wrapped }
+	fresh := func() string {
+		return limitLines(gm.wrapMessage(plain, styles, sc, gm.maxLineWidth), gm.fontFace, gm.maxLines, gm.maxLineWidth)
+	}
+
+	origFace := gm.fontFace
+	wide := wrap()
+	if got := wrap(); got != wide || gm.wrapCache.order.Len() != 1 {
+		t.Fatalf("second wrap = %q with %d entries, want the cached %q", got, gm.wrapCache.order.Len(), wide)
+	}
+
+	// 幅を変えると折り返し直す
+	gm.maxLineWidth = 150
+	narrow := wrap()
+	if narrow == wide || narrow != fresh() {
+		t.Errorf("wrap at width 150 = %q, want %q", narrow, fresh())
+	}
+
+	// フォントを変えると折り返し直す
+	face, err := newFontFaces(gm.fonts, 12, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gm.fontFace = face
+	small := wrap()
+	if small == narrow || small != fresh() {
+		t.Errorf("wrap with a smaller font = %q, want %q", small, fresh())
+	}
+
+	// 元の幅とフォントに戻すと、覚えておいた結果を使う
+	gm.maxLineWidth, gm.fontFace = defaultMaxLineWidth, origFace
+	if got, n := wrap(), gm.wrapCache.order.Len(); got != wide || n != 3 {
+		t.Errorf("wrap after restoring = %q with %d entries, want the cached %q with 3", got, n, wide)
+	}
+}
+
+func BenchmarkWrapCached(b *testing.B) {
+	gm := newTestGame(b, DefaultOptions())
+	src := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20)
+	plain, styles := parseMarkup(parseANSIColors(src))
+	sc := detectScript(plain)
+
+	b.Run("hit", func(b *testing.B) {
+		gm.wrapCache = newWrapCache()
+		gm.wrapCached(src, plain, styles, sc)
+		for b.Loop() {
+			gm.wrapCached(src, plain, styles, sc)
+		}
+	})
+	b.Run("miss", func(b *testing.B) {
+		for b.Loop() {
+			gm.wrapCache = newWrapCache()
+			gm.wrapCached(src, plain, styles, sc)
+		}
+	})
+}